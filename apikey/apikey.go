@@ -0,0 +1,72 @@
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/stepanbukhtii/easy-tools/econtext"
+)
+
+// DefaultHeader carries API key when no other header is configured.
+const DefaultHeader = "X-API-Key"
+
+var (
+	ErrKeyMissing = errors.New("api key missing")
+	ErrKeyInvalid = errors.New("api key invalid")
+	ErrKeyExpired = errors.New("api key expired")
+	ErrNotFound   = errors.New("api key not found")
+)
+
+// Key describes an API key owner, only the hash of the plain key is stored.
+type Key struct {
+	ID         string    `json:"id"`
+	Hash       string    `json:"hash"`
+	Subject    string    `json:"subject"`
+	Roles      []string  `json:"roles,omitempty"`
+	Scopes     []string  `json:"scopes,omitempty"`
+	ExpiresAt  time.Time `json:"expires_at,omitzero"`
+	LastUsedAt time.Time `json:"last_used_at,omitzero"`
+}
+
+// Expired reports whether key has expiration time in the past.
+func (k Key) Expired(now time.Time) bool {
+	return !k.ExpiresAt.IsZero() && !now.Before(k.ExpiresAt)
+}
+
+// SetContext attaches key owner to econtext.ClientInfo.
+func (k Key) SetContext(ctx context.Context) context.Context {
+	ctx = econtext.SetSubject(ctx, k.Subject)
+
+	if k.Roles != nil {
+		ctx = econtext.SetRoles(ctx, k.Roles)
+	}
+
+	if k.Scopes != nil {
+		ctx = econtext.SetScopes(ctx, k.Scopes)
+	}
+
+	return ctx
+}
+
+// Hash returns hex encoded sha256 of plain key, API keys have enough entropy for a fast hash.
+func Hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Generate returns new random plain key with optional prefix and its hash.
+func Generate(prefix string) (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+
+	key := prefix + base64.RawURLEncoding.EncodeToString(b)
+
+	return key, Hash(key), nil
+}
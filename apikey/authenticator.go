@@ -0,0 +1,147 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/stepanbukhtii/easy-tools/elog"
+)
+
+var (
+	DefaultTouchInterval = time.Minute
+	DefaultTouchTimeout  = 5 * time.Second
+	touchQueueSize       = 1024
+)
+
+type Option func(a *Authenticator)
+
+// WithTouchInterval sets how often last used time of the same key is written to store.
+func WithTouchInterval(d time.Duration) Option {
+	return func(a *Authenticator) { a.touchInterval = d }
+}
+
+type touch struct {
+	hash   string
+	usedAt time.Time
+}
+
+// Authenticator validates plain API keys against Store. Last used time is written
+// asynchronously by a background worker, updates are dropped when the queue is full.
+type Authenticator struct {
+	store         Store
+	touchInterval time.Duration
+
+	mu        sync.Mutex
+	closed    bool
+	lastTouch map[string]time.Time
+	lastPrune time.Time
+	touches   chan touch
+	done      chan struct{}
+}
+
+func NewAuthenticator(store Store, opts ...Option) *Authenticator {
+	a := &Authenticator{
+		store:         store,
+		touchInterval: DefaultTouchInterval,
+		lastTouch:     make(map[string]time.Time),
+		touches:       make(chan touch, touchQueueSize),
+		done:          make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	go a.touchWorker()
+
+	return a
+}
+
+// Authenticate returns key owner of plain API key.
+func (a *Authenticator) Authenticate(ctx context.Context, plainKey string) (Key, error) {
+	if plainKey == "" {
+		return Key{}, ErrKeyMissing
+	}
+
+	hash := Hash(plainKey)
+
+	key, err := a.store.Get(ctx, hash)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return Key{}, ErrKeyInvalid
+	case err != nil:
+		return Key{}, err
+	}
+
+	now := time.Now()
+	if key.Expired(now) {
+		return Key{}, ErrKeyExpired
+	}
+
+	a.touch(hash, now)
+
+	return key, nil
+}
+
+// Close stops last used worker after pending updates are written, keys authenticated
+// after Close are not touched.
+func (a *Authenticator) Close() {
+	a.mu.Lock()
+	if !a.closed {
+		a.closed = true
+		close(a.touches)
+	}
+	a.mu.Unlock()
+
+	<-a.done
+}
+
+func (a *Authenticator) touch(hash string, now time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.closed {
+		return
+	}
+
+	a.pruneLastTouch(now)
+
+	if last, ok := a.lastTouch[hash]; ok && now.Sub(last) < a.touchInterval {
+		return
+	}
+
+	select {
+	case a.touches <- touch{hash: hash, usedAt: now}:
+		a.lastTouch[hash] = now
+	default:
+	}
+}
+
+// pruneLastTouch removes keys not used within touch interval, they would be touched anyway
+func (a *Authenticator) pruneLastTouch(now time.Time) {
+	if now.Sub(a.lastPrune) < a.touchInterval {
+		return
+	}
+	a.lastPrune = now
+
+	for hash, last := range a.lastTouch {
+		if now.Sub(last) >= a.touchInterval {
+			delete(a.lastTouch, hash)
+		}
+	}
+}
+
+func (a *Authenticator) touchWorker() {
+	defer close(a.done)
+
+	for t := range a.touches {
+		ctx, cancel := context.WithTimeout(context.Background(), DefaultTouchTimeout)
+		if err := a.store.Touch(ctx, t.hash, t.usedAt); err != nil {
+			slog.With(elog.Err(err)).ErrorContext(ctx, "api key last used update failed")
+		}
+		cancel()
+	}
+}
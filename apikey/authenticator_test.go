@@ -0,0 +1,114 @@
+package apikey
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/stepanbukhtii/easy-tools/cache"
+	"github.com/stepanbukhtii/easy-tools/econtext"
+)
+
+func TestAuthenticator(t *testing.T) {
+	ctx := context.Background()
+
+	plainKey, hash, err := Generate("pk_")
+	require.NoError(t, err)
+	require.Equal(t, Hash(plainKey), hash)
+
+	expiredKey, expiredHash, err := Generate("pk_")
+	require.NoError(t, err)
+
+	memoryCache := cache.NewMemory[Key](time.Hour, time.Minute)
+	defer memoryCache.Close()
+
+	cacheStore := NewCacheStore(memoryCache)
+
+	stores := map[string]Store{
+		"memory": NewMemoryStore(),
+		"cache":  cacheStore,
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			keys := []Key{
+				{ID: "1", Hash: hash, Subject: "partner", Roles: []string{"partner"}, Scopes: []string{"orders:read"}},
+				{ID: "2", Hash: expiredHash, Subject: "partner", ExpiresAt: time.Now().Add(-time.Minute)},
+			}
+			for _, key := range keys {
+				switch s := store.(type) {
+				case *MemoryStore:
+					s.Add(key)
+				case *CacheStore:
+					require.NoError(t, s.Add(ctx, key))
+				}
+			}
+
+			authenticator := NewAuthenticator(store)
+
+			key, err := authenticator.Authenticate(ctx, plainKey)
+			require.NoError(t, err)
+			require.Equal(t, "partner", key.Subject)
+
+			info := econtext.ClientInfo(key.SetContext(ctx))
+			require.Equal(t, "partner", info.Subject)
+			require.Equal(t, []string{"partner"}, info.Roles)
+			require.Equal(t, []string{"orders:read"}, info.Scopes)
+
+			_, err = authenticator.Authenticate(ctx, "")
+			require.ErrorIs(t, err, ErrKeyMissing)
+
+			_, err = authenticator.Authenticate(ctx, "unknown")
+			require.ErrorIs(t, err, ErrKeyInvalid)
+
+			_, err = authenticator.Authenticate(ctx, expiredKey)
+			require.ErrorIs(t, err, ErrKeyExpired)
+
+			authenticator.Close()
+
+			stored, err := store.Get(ctx, hash)
+			require.NoError(t, err)
+			require.False(t, stored.LastUsedAt.IsZero())
+		})
+	}
+}
+
+func TestAuthenticator_Close(t *testing.T) {
+	ctx := context.Background()
+
+	plainKey, hash, err := Generate("pk_")
+	require.NoError(t, err)
+
+	store := NewMemoryStore()
+	store.Add(Key{ID: "1", Hash: hash, Subject: "partner"})
+
+	authenticator := NewAuthenticator(store, WithTouchInterval(0))
+	authenticator.Close()
+	authenticator.Close()
+
+	_, err = authenticator.Authenticate(ctx, plainKey)
+	require.NoError(t, err)
+
+	stored, err := store.Get(ctx, hash)
+	require.NoError(t, err)
+	require.True(t, stored.LastUsedAt.IsZero())
+}
+
+func TestCacheStore_TouchRemoved(t *testing.T) {
+	ctx := context.Background()
+
+	memoryCache := cache.NewMemory[Key](time.Hour, time.Minute)
+	defer memoryCache.Close()
+
+	store := NewCacheStore(memoryCache)
+	require.NoError(t, store.Add(ctx, Key{ID: "1", Hash: "hash", Subject: "partner"}))
+	require.NoError(t, store.Touch(ctx, "hash", time.Now()))
+
+	require.NoError(t, store.Remove(ctx, "hash"))
+	require.ErrorIs(t, store.Touch(ctx, "hash", time.Now()), ErrNotFound)
+
+	_, err := store.Get(ctx, "hash")
+	require.ErrorIs(t, err, ErrNotFound)
+}
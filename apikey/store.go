@@ -0,0 +1,130 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/stepanbukhtii/easy-tools/cache"
+)
+
+// Store looks up keys by hash.
+type Store interface {
+	Get(ctx context.Context, hash string) (Key, error)
+	Touch(ctx context.Context, hash string, usedAt time.Time) error
+}
+
+type MemoryStore struct {
+	mu   sync.RWMutex
+	keys map[string]Key
+}
+
+func NewMemoryStore(keys ...Key) *MemoryStore {
+	s := &MemoryStore{keys: make(map[string]Key, len(keys))}
+	for _, key := range keys {
+		s.keys[key.Hash] = key
+	}
+	return s
+}
+
+func (s *MemoryStore) Add(key Key) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys[key.Hash] = key
+}
+
+func (s *MemoryStore) Remove(hash string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.keys, hash)
+}
+
+func (s *MemoryStore) Get(_ context.Context, hash string) (Key, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	key, ok := s.keys[hash]
+	if !ok {
+		return Key{}, ErrNotFound
+	}
+
+	return key, nil
+}
+
+func (s *MemoryStore) Touch(_ context.Context, hash string, usedAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.keys[hash]
+	if !ok {
+		return ErrNotFound
+	}
+
+	key.LastUsedAt = usedAt
+	s.keys[hash] = key
+
+	return nil
+}
+
+// CacheStore keeps keys in cache.Cache under "apikey:<hash>", last used time is kept
+// under "apikey:used:<hash>" so touching never recreates a removed key.
+type CacheStore struct {
+	cache cache.Cache[Key]
+}
+
+// NewCacheStore stores keys with the TTL of c, keys added with Add disappear when it
+// expires, so use cache with TTL not shorter than key lifetime or add keys again on load.
+func NewCacheStore(c cache.Cache[Key]) *CacheStore {
+	return &CacheStore{cache: c}
+}
+
+func (s *CacheStore) Add(ctx context.Context, key Key) error {
+	return s.cache.Set(ctx, cacheKey(key.Hash), key)
+}
+
+func (s *CacheStore) Remove(ctx context.Context, hash string) error {
+	return s.cache.Delete(ctx, cacheKey(hash), usedCacheKey(hash))
+}
+
+func (s *CacheStore) Get(ctx context.Context, hash string) (Key, error) {
+	key, err := s.cache.Get(ctx, cacheKey(hash))
+	if errors.Is(err, cache.ErrNotFound) {
+		return Key{}, ErrNotFound
+	}
+	if err != nil {
+		return Key{}, err
+	}
+
+	used, err := s.cache.Get(ctx, usedCacheKey(hash))
+	switch {
+	case err == nil:
+		key.LastUsedAt = used.LastUsedAt
+	case !errors.Is(err, cache.ErrNotFound):
+		return Key{}, err
+	}
+
+	return key, nil
+}
+
+func (s *CacheStore) Touch(ctx context.Context, hash string, usedAt time.Time) error {
+	exists, err := s.cache.Exists(ctx, cacheKey(hash))
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrNotFound
+	}
+
+	return s.cache.Set(ctx, usedCacheKey(hash), Key{Hash: hash, LastUsedAt: usedAt})
+}
+
+func cacheKey(hash string) string {
+	return "apikey:" + hash
+}
+
+func usedCacheKey(hash string) string {
+	return "apikey:used:" + hash
+}
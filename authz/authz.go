@@ -0,0 +1,133 @@
+package authz
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/stepanbukhtii/easy-tools/econtext"
+	"github.com/stepanbukhtii/easy-tools/rest/client"
+)
+
+const Wildcard = "*"
+
+var (
+	ErrUnauthenticated  = errors.New("unauthenticated")
+	ErrPermissionDenied = errors.New("permission denied")
+)
+
+// Permission allows action on resource, Wildcard matches any resource or action.
+type Permission struct {
+	Resource string
+	Action   string
+}
+
+// ParsePermission parses permission in "resource:action" format.
+func ParsePermission(permission string) Permission {
+	resource, action, _ := strings.Cut(permission, ":")
+	return Permission{Resource: resource, Action: action}
+}
+
+func (p Permission) String() string {
+	return p.Resource + ":" + p.Action
+}
+
+func (p Permission) covers(required Permission) bool {
+	return (p.Resource == Wildcard || p.Resource == required.Resource) &&
+		(p.Action == Wildcard || p.Action == required.Action)
+}
+
+// Principal is authenticated caller with roles expanded by inheritance.
+type Principal struct {
+	Subject     string
+	Roles       map[string]bool
+	Scopes      map[string]bool
+	Permissions []Permission
+}
+
+func (p Principal) Can(required Permission) bool {
+	for _, permission := range p.Permissions {
+		if permission.covers(required) {
+			return true
+		}
+	}
+	return false
+}
+
+// Authorizer holds role hierarchy and role permissions, it is configured on start
+// and is safe for concurrent use afterwards.
+type Authorizer struct {
+	inherits    map[string][]string
+	permissions map[string][]Permission
+}
+
+func NewAuthorizer() *Authorizer {
+	return &Authorizer{
+		inherits:    make(map[string][]string),
+		permissions: make(map[string][]Permission),
+	}
+}
+
+// Inherit makes role imply inherited roles, e.g. admin implies editor.
+func (a *Authorizer) Inherit(role string, inherited ...string) *Authorizer {
+	a.inherits[role] = append(a.inherits[role], inherited...)
+	return a
+}
+
+// Grant adds permissions to role, roles inheriting it get them too.
+func (a *Authorizer) Grant(role string, permissions ...Permission) *Authorizer {
+	a.permissions[role] = append(a.permissions[role], permissions...)
+	return a
+}
+
+// Principal builds principal from client info.
+func (a *Authorizer) Principal(info client.Info) Principal {
+	principal := Principal{
+		Subject: info.Subject,
+		Roles:   make(map[string]bool),
+		Scopes:  make(map[string]bool, len(info.Scopes)),
+	}
+
+	roles := append([]string{}, info.Roles...)
+	for len(roles) > 0 {
+		role := roles[len(roles)-1]
+		roles = roles[:len(roles)-1]
+
+		if principal.Roles[role] {
+			continue
+		}
+		principal.Roles[role] = true
+
+		principal.Permissions = append(principal.Permissions, a.permissions[role]...)
+		roles = append(roles, a.inherits[role]...)
+	}
+
+	for _, scope := range info.Scopes {
+		principal.Scopes[scope] = true
+	}
+
+	return principal
+}
+
+// Authorize evaluates policy for client info, returns ErrUnauthenticated when
+// there is no subject and ErrPermissionDenied when policy is not satisfied.
+func (a *Authorizer) Authorize(info client.Info, policy Policy) error {
+	if policy == nil {
+		return nil
+	}
+
+	if info.Subject == "" {
+		return ErrUnauthenticated
+	}
+
+	if !policy(a.Principal(info)) {
+		return ErrPermissionDenied
+	}
+
+	return nil
+}
+
+// AuthorizeContext evaluates policy for econtext.ClientInfo.
+func (a *Authorizer) AuthorizeContext(ctx context.Context, policy Policy) error {
+	return a.Authorize(econtext.ClientInfo(ctx), policy)
+}
@@ -0,0 +1,82 @@
+package authz
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/stepanbukhtii/easy-tools/rest/client"
+)
+
+func TestAuthorizer(t *testing.T) {
+	authorizer := NewAuthorizer().
+		Inherit("admin", "editor").
+		Inherit("editor", "viewer").
+		Inherit("viewer", "admin").
+		Grant("viewer", ParsePermission("articles:read")).
+		Grant("editor", Permission{Resource: "articles", Action: Wildcard}).
+		Grant("billing", Permission{Resource: "invoices", Action: "read"})
+
+	tests := []struct {
+		name        string
+		info        client.Info
+		policy      Policy
+		expectedErr error
+	}{
+		{
+			name:   "inherited role",
+			info:   client.Info{Subject: "user", Roles: []string{"admin"}},
+			policy: AllRoles("editor", "viewer"),
+		}, {
+			name:        "not inherited role",
+			info:        client.Info{Subject: "user", Roles: []string{"editor"}},
+			policy:      AnyRole("billing"),
+			expectedErr: ErrPermissionDenied,
+		}, {
+			name:   "any role",
+			info:   client.Info{Subject: "user", Roles: []string{"billing"}},
+			policy: AnyRole("admin", "billing"),
+		}, {
+			name:        "all roles",
+			info:        client.Info{Subject: "user", Roles: []string{"billing"}},
+			policy:      AllRoles("admin", "billing"),
+			expectedErr: ErrPermissionDenied,
+		}, {
+			name:   "scopes",
+			info:   client.Info{Subject: "client", Scopes: []string{"orders:read", "orders:write"}},
+			policy: AllOf(AnyScope("orders:read"), AllScopes("orders:read", "orders:write")),
+		}, {
+			name:        "missing scope",
+			info:        client.Info{Subject: "client", Scopes: []string{"orders:read"}},
+			policy:      AllScopes("orders:read", "orders:write"),
+			expectedErr: ErrPermissionDenied,
+		}, {
+			name:   "wildcard permission",
+			info:   client.Info{Subject: "user", Roles: []string{"admin"}},
+			policy: Can("articles", "delete"),
+		}, {
+			name:        "permission of other resource",
+			info:        client.Info{Subject: "user", Roles: []string{"viewer"}},
+			policy:      Can("invoices", "read"),
+			expectedErr: ErrPermissionDenied,
+		}, {
+			name:   "any of",
+			info:   client.Info{Subject: "user", Roles: []string{"viewer"}},
+			policy: AnyOf(Can("invoices", "read"), AnyScope("invoices:read"), Authenticated()),
+		}, {
+			name:        "unauthenticated",
+			info:        client.Info{Roles: []string{"admin"}},
+			policy:      AnyRole("admin"),
+			expectedErr: ErrUnauthenticated,
+		}, {
+			name: "nil policy",
+			info: client.Info{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.ErrorIs(t, authorizer.Authorize(test.info, test.policy), test.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package authz
+
+// Policy decides whether principal is allowed.
+type Policy func(p Principal) bool
+
+// Authenticated allows any principal with subject.
+func Authenticated() Policy {
+	return func(p Principal) bool { return p.Subject != "" }
+}
+
+func AnyRole(roles ...string) Policy {
+	return func(p Principal) bool {
+		for _, role := range roles {
+			if p.Roles[role] {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func AllRoles(roles ...string) Policy {
+	return func(p Principal) bool {
+		for _, role := range roles {
+			if !p.Roles[role] {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+func AnyScope(scopes ...string) Policy {
+	return func(p Principal) bool {
+		for _, scope := range scopes {
+			if p.Scopes[scope] {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func AllScopes(scopes ...string) Policy {
+	return func(p Principal) bool {
+		for _, scope := range scopes {
+			if !p.Scopes[scope] {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Can allows principal with permission for action on resource granted to any of its roles.
+func Can(resource, action string) Policy {
+	required := Permission{Resource: resource, Action: action}
+	return func(p Principal) bool { return p.Can(required) }
+}
+
+func AnyOf(policies ...Policy) Policy {
+	return func(p Principal) bool {
+		for _, policy := range policies {
+			if policy(p) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func AllOf(policies ...Policy) Policy {
+	return func(p Principal) bool {
+		for _, policy := range policies {
+			if !policy(p) {
+				return false
+			}
+		}
+		return true
+	}
+}
@@ -20,6 +20,12 @@ type Cache[T any] interface {
 	Delete(ctx context.Context, keys ...string) error
 }
 
+// TTLCache allows to set TTL per key instead of the cache TTL.
+type TTLCache[T any] interface {
+	Cache[T]
+	SetTTL(ctx context.Context, key string, value T, ttl time.Duration) error
+}
+
 type MapCache[T any] interface {
 	Get(ctx context.Context, key string) (T, error)
 	GetAll(ctx context.Context) ([]T, error)
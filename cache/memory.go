@@ -140,6 +140,18 @@ func (c *Memory[T]) Set(_ context.Context, key string, value T) error {
 	return nil
 }
 
+func (c *Memory[T]) SetTTL(_ context.Context, key string, value T, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.data[key] = entry[T]{
+		value:     value,
+		expiresAt: time.Now().Add(ttl),
+	}
+
+	return nil
+}
+
 func (c *Memory[T]) SetAll(_ context.Context, values []T, keyFunc func(v T) string) error {
 	expiresAt := time.Now().Add(c.ttl)
 
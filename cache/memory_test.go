@@ -74,6 +74,21 @@ func TestMemory(t *testing.T) {
 		require.Equal(t, expectedValues, valuesMap)
 	})
 
+	t.Run("SetTTL", func(t *testing.T) {
+		cache := NewMemory[string](time.Minute, time.Second)
+
+		require.NoError(t, cache.SetTTL(ctx, key, keyValue, -time.Second))
+
+		_, err := cache.Get(ctx, key)
+		require.ErrorIs(t, err, ErrNotFound)
+
+		require.NoError(t, cache.SetTTL(ctx, key, keyValue, time.Hour))
+
+		value, err := cache.Get(ctx, key)
+		require.NoError(t, err)
+		require.Equal(t, keyValue, value)
+	})
+
 	t.Run("SetNX", func(t *testing.T) {
 		cache := NewMemory[string](time.Minute, time.Second)
 
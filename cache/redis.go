@@ -19,7 +19,7 @@ type Redis[T any] struct {
 	ttl         time.Duration
 }
 
-func NewRedis[T any](client *redis.Client, serviceName, keyPrefix string, ttl time.Duration) Cache[T] {
+func NewRedis[T any](client *redis.Client, serviceName, keyPrefix string, ttl time.Duration) TTLCache[T] {
 	return &Redis[T]{
 		client:      client,
 		serviceName: serviceName,
@@ -61,6 +61,15 @@ func (c *Redis[T]) Set(ctx context.Context, key string, value T) error {
 	return c.client.Set(ctx, c.key(key), data, c.ttl).Err()
 }
 
+func (c *Redis[T]) SetTTL(ctx context.Context, key string, value T, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return c.client.Set(ctx, c.key(key), data, ttl).Err()
+}
+
 func (c *Redis[T]) SetNX(ctx context.Context, key string, value T) (bool, error) {
 	data, err := jsoniter.Marshal(value)
 	if err != nil {
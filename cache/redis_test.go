@@ -53,6 +53,14 @@ func TestRedis(t *testing.T) {
 	require.False(t, ok)
 
 	require.NoError(t, cache.Delete(ctx, keySetNX))
+
+	require.NoError(t, cache.SetTTL(ctx, key, keyValue, time.Second))
+
+	value, err = cache.Get(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, keyValue, value)
+
+	require.NoError(t, cache.Delete(ctx, key))
 }
 
 func TestRedisMap(t *testing.T) {
@@ -30,7 +30,26 @@ type JWT struct {
 }
 
 type GRPC struct {
-	Port string `env:"GRPC_PORT"`
+	Port          string `env:"GRPC_PORT"`
+	TLSEnabled    bool   `env:"GRPC_TLS_ENABLED"`
+	TLSCertFile   string `env:"GRPC_TLS_CERT_FILE"`
+	TLSKeyFile    string `env:"GRPC_TLS_KEY_FILE"`
+	TLSCAFile     string `env:"GRPC_TLS_CA_FILE"`
+	TLSClientAuth string `env:"GRPC_TLS_CLIENT_AUTH"`
+	TLSServerName string `env:"GRPC_TLS_SERVER_NAME"`
+
+	HealthEnabled     bool          `env:"GRPC_HEALTH_ENABLED"`
+	ReflectionEnabled bool          `env:"GRPC_REFLECTION_ENABLED"`
+	ShutdownTimeout   time.Duration `env:"GRPC_SHUTDOWN_TIMEOUT"`
+
+	MaxRecvMsgSize        int           `env:"GRPC_MAX_RECV_MSG_SIZE"`
+	MaxSendMsgSize        int           `env:"GRPC_MAX_SEND_MSG_SIZE"`
+	KeepaliveTime         time.Duration `env:"GRPC_KEEPALIVE_TIME"`
+	KeepaliveTimeout      time.Duration `env:"GRPC_KEEPALIVE_TIMEOUT"`
+	KeepaliveMinTime      time.Duration `env:"GRPC_KEEPALIVE_MIN_TIME"`
+	MaxConnectionIdle     time.Duration `env:"GRPC_MAX_CONNECTION_IDLE"`
+	MaxConnectionAge      time.Duration `env:"GRPC_MAX_CONNECTION_AGE"`
+	MaxConnectionAgeGrace time.Duration `env:"GRPC_MAX_CONNECTION_AGE_GRACE"`
 }
 
 type GRPCAuthJWT struct {
@@ -0,0 +1,347 @@
+package crypto
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+)
+
+const (
+	envelopeVersion     = 1
+	envelopePrefixSize  = 7
+	envelopeMaxChunk    = 16 << 20
+	envelopeDataKeySize = 32
+)
+
+var (
+	envelopeMagic = []byte("EENV")
+
+	DefaultEnvelopeChunkSize = 64 << 10
+)
+
+// EnvelopeEncrypt encrypts plaintext with a new data key wrapped by the KMS.
+// Result starts with a header holding the master key ID and wrapped data key,
+// so EnvelopeDecrypt needs nothing except the KMS.
+func EnvelopeEncrypt(ctx context.Context, kms KMS, plaintext []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	w, err := NewEnvelopeWriter(ctx, kms, &buf)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := w.Write(plaintext); err != nil {
+		return nil, err
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func EnvelopeDecrypt(ctx context.Context, kms KMS, ciphertext []byte) ([]byte, error) {
+	r, err := NewEnvelopeReader(ctx, kms, bytes.NewReader(ciphertext))
+	if err != nil {
+		return nil, err
+	}
+
+	return io.ReadAll(r)
+}
+
+// NewEnvelopeWriter writes envelope header to w and returns writer encrypting data
+// in chunks of DefaultEnvelopeChunkSize. Close must be called to write the final chunk.
+func NewEnvelopeWriter(ctx context.Context, kms KMS, w io.Writer) (io.WriteCloser, error) {
+	dataKey := make([]byte, envelopeDataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, err
+	}
+
+	wrappedKey, err := kms.Encrypt(ctx, dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := newEnvelopeAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	h := envelopeHeader{
+		wrappedKey: wrappedKey,
+		chunkSize:  DefaultEnvelopeChunkSize,
+		prefix:     make([]byte, envelopePrefixSize),
+	}
+	if _, err := rand.Read(h.prefix); err != nil {
+		return nil, err
+	}
+
+	header, err := h.marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+
+	return &envelopeWriter{
+		w:         w,
+		aead:      aead,
+		header:    header,
+		prefix:    h.prefix,
+		chunkSize: h.chunkSize,
+		buf:       make([]byte, 0, h.chunkSize),
+	}, nil
+}
+
+// NewEnvelopeReader reads envelope header from r, unwraps data key with the KMS
+// and returns reader decrypting data chunk by chunk.
+func NewEnvelopeReader(ctx context.Context, kms KMS, r io.Reader) (io.Reader, error) {
+	h, header, err := readEnvelopeHeader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	dataKey, err := kms.Decrypt(ctx, h.wrappedKey)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := newEnvelopeAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &envelopeReader{
+		r:      bufio.NewReader(r),
+		aead:   aead,
+		header: header,
+		prefix: h.prefix,
+		buf:    make([]byte, h.chunkSize+aead.Overhead()),
+	}, nil
+}
+
+type envelopeHeader struct {
+	wrappedKey WrappedKey
+	chunkSize  int
+	prefix     []byte
+}
+
+func (h envelopeHeader) marshal() ([]byte, error) {
+	if len(h.wrappedKey.KeyID) > math.MaxUint16 || len(h.wrappedKey.Ciphertext) > math.MaxUint16 {
+		return nil, ErrEnvelopeHeader
+	}
+
+	header := append([]byte{}, envelopeMagic...)
+	header = append(header, envelopeVersion)
+	header = binary.BigEndian.AppendUint16(header, uint16(len(h.wrappedKey.KeyID)))
+	header = append(header, h.wrappedKey.KeyID...)
+	header = binary.BigEndian.AppendUint16(header, uint16(len(h.wrappedKey.Ciphertext)))
+	header = append(header, h.wrappedKey.Ciphertext...)
+	header = binary.BigEndian.AppendUint32(header, uint32(h.chunkSize))
+	header = append(header, h.prefix...)
+
+	return header, nil
+}
+
+func readEnvelopeHeader(r io.Reader) (envelopeHeader, []byte, error) {
+	var h envelopeHeader
+	var header bytes.Buffer
+	tr := io.TeeReader(r, &header)
+
+	start := make([]byte, len(envelopeMagic)+1)
+	if _, err := io.ReadFull(tr, start); err != nil {
+		return h, nil, ErrEnvelopeHeader
+	}
+	if !bytes.Equal(start[:len(envelopeMagic)], envelopeMagic) || start[len(envelopeMagic)] != envelopeVersion {
+		return h, nil, ErrEnvelopeHeader
+	}
+
+	keyID, err := readEnvelopeField(tr)
+	if err != nil {
+		return h, nil, err
+	}
+
+	ciphertext, err := readEnvelopeField(tr)
+	if err != nil {
+		return h, nil, err
+	}
+
+	chunk := make([]byte, 4+envelopePrefixSize)
+	if _, err := io.ReadFull(tr, chunk); err != nil {
+		return h, nil, ErrEnvelopeHeader
+	}
+
+	h.wrappedKey = WrappedKey{KeyID: string(keyID), Ciphertext: ciphertext}
+	h.chunkSize = int(binary.BigEndian.Uint32(chunk[:4]))
+	h.prefix = chunk[4:]
+
+	if h.chunkSize == 0 || h.chunkSize > envelopeMaxChunk {
+		return h, nil, ErrEnvelopeHeader
+	}
+
+	return h, header.Bytes(), nil
+}
+
+func readEnvelopeField(r io.Reader) ([]byte, error) {
+	size := make([]byte, 2)
+	if _, err := io.ReadFull(r, size); err != nil {
+		return nil, ErrEnvelopeHeader
+	}
+
+	field := make([]byte, binary.BigEndian.Uint16(size))
+	if _, err := io.ReadFull(r, field); err != nil {
+		return nil, ErrEnvelopeHeader
+	}
+
+	return field, nil
+}
+
+type envelopeWriter struct {
+	w         io.Writer
+	aead      cipher.AEAD
+	header    []byte
+	prefix    []byte
+	counter   uint32
+	chunkSize int
+	buf       []byte
+	closed    bool
+}
+
+func (w *envelopeWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, io.ErrClosedPipe
+	}
+
+	var n int
+	for len(p) > 0 {
+		// the chunk is flushed only when more data arrives, so Close always has a chunk to mark as last
+		if len(w.buf) == w.chunkSize {
+			if err := w.flush(false); err != nil {
+				return n, err
+			}
+		}
+
+		size := min(w.chunkSize-len(w.buf), len(p))
+		w.buf = append(w.buf, p[:size]...)
+		p = p[size:]
+		n += size
+	}
+
+	return n, nil
+}
+
+func (w *envelopeWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+
+	return w.flush(true)
+}
+
+func (w *envelopeWriter) flush(last bool) error {
+	nonce := envelopeNonce(w.prefix, w.counter, last)
+	w.counter++
+
+	if _, err := w.w.Write(w.aead.Seal(nil, nonce, w.buf, w.header)); err != nil {
+		return err
+	}
+
+	w.buf = w.buf[:0]
+
+	return nil
+}
+
+type envelopeReader struct {
+	r       *bufio.Reader
+	aead    cipher.AEAD
+	header  []byte
+	prefix  []byte
+	counter uint32
+	buf     []byte
+	plain   []byte
+	done    bool
+}
+
+func (r *envelopeReader) Read(p []byte) (int, error) {
+	for len(r.plain) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+
+		if err := r.readChunk(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, r.plain)
+	r.plain = r.plain[n:]
+
+	return n, nil
+}
+
+func (r *envelopeReader) readChunk() error {
+	n, err := io.ReadFull(r.r, r.buf)
+
+	var last bool
+	switch {
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		last = true
+	case err != nil:
+		return err
+	default:
+		if _, err := r.r.Peek(1); errors.Is(err, io.EOF) {
+			last = true
+		} else if err != nil {
+			return err
+		}
+	}
+
+	if n < r.aead.Overhead() {
+		return ErrEnvelopeCorrupted
+	}
+
+	nonce := envelopeNonce(r.prefix, r.counter, last)
+	r.counter++
+
+	plain, err := r.aead.Open(r.buf[:0], nonce, r.buf[:n], r.header)
+	if err != nil {
+		return ErrEnvelopeCorrupted
+	}
+
+	r.plain = plain
+	r.done = last
+
+	return nil
+}
+
+func newEnvelopeAEAD(dataKey []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// envelopeNonce builds chunk nonce from random prefix, chunk counter and last chunk flag,
+// which prevents reordering, dropping and truncating chunks.
+func envelopeNonce(prefix []byte, counter uint32, last bool) []byte {
+	nonce := make([]byte, 0, envelopePrefixSize+5)
+	nonce = append(nonce, prefix...)
+	nonce = binary.BigEndian.AppendUint32(nonce, counter)
+	if last {
+		return append(nonce, 1)
+	}
+	return append(nonce, 0)
+}
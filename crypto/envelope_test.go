@@ -0,0 +1,103 @@
+package crypto
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvelope(t *testing.T) {
+	ctx := context.Background()
+
+	masterKey := make([]byte, 32)
+	_, err := rand.Read(masterKey)
+	require.NoError(t, err)
+
+	keyPath := filepath.Join(t.TempDir(), "master.key")
+	require.NoError(t, os.WriteFile(keyPath, []byte(base64.StdEncoding.EncodeToString(masterKey)), 0o600))
+
+	kms, err := NewLocalKMSFile("master-1", keyPath)
+	require.NoError(t, err)
+
+	t.Run("sizes", func(t *testing.T) {
+		for _, size := range []int{0, 1, DefaultEnvelopeChunkSize, 3*DefaultEnvelopeChunkSize + 17} {
+			plaintext := make([]byte, size)
+			_, err := rand.Read(plaintext)
+			require.NoError(t, err)
+
+			ciphertext, err := EnvelopeEncrypt(ctx, kms, plaintext)
+			require.NoError(t, err)
+
+			decrypted, err := EnvelopeDecrypt(ctx, kms, ciphertext)
+			require.NoError(t, err)
+			require.Equal(t, plaintext, append([]byte{}, decrypted...))
+		}
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		plaintext := bytes.Repeat([]byte("large payload "), 1<<18)
+
+		var encrypted bytes.Buffer
+		w, err := NewEnvelopeWriter(ctx, kms, &encrypted)
+		require.NoError(t, err)
+
+		_, err = io.Copy(w, bytes.NewReader(plaintext))
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+
+		r, err := NewEnvelopeReader(ctx, kms, &encrypted)
+		require.NoError(t, err)
+
+		decrypted, err := io.ReadAll(r)
+		require.NoError(t, err)
+		require.Equal(t, plaintext, decrypted)
+	})
+
+	t.Run("tampered and truncated", func(t *testing.T) {
+		plaintext := bytes.Repeat([]byte{1}, 2*DefaultEnvelopeChunkSize+1)
+
+		ciphertext, err := EnvelopeEncrypt(ctx, kms, plaintext)
+		require.NoError(t, err)
+
+		tampered := append([]byte{}, ciphertext...)
+		tampered[len(tampered)-1] ^= 1
+		_, err = EnvelopeDecrypt(ctx, kms, tampered)
+		require.ErrorIs(t, err, ErrEnvelopeCorrupted)
+
+		// drop the final chunk of one byte and GCM tag, the previous chunk is not marked as last
+		truncated := ciphertext[:len(ciphertext)-17]
+		_, err = EnvelopeDecrypt(ctx, kms, truncated)
+		require.ErrorIs(t, err, ErrEnvelopeCorrupted)
+
+		_, err = EnvelopeDecrypt(ctx, kms, []byte("not an envelope"))
+		require.ErrorIs(t, err, ErrEnvelopeHeader)
+	})
+
+	t.Run("rotated master key", func(t *testing.T) {
+		ciphertext, err := EnvelopeEncrypt(ctx, kms, []byte("secret"))
+		require.NoError(t, err)
+
+		newMasterKey := make([]byte, 32)
+		_, err = rand.Read(newMasterKey)
+		require.NoError(t, err)
+
+		rotatedKMS, err := NewLocalKMS("master-2", newMasterKey)
+		require.NoError(t, err)
+
+		_, err = EnvelopeDecrypt(ctx, rotatedKMS, ciphertext)
+		require.ErrorIs(t, err, ErrMasterKeyNotFound)
+
+		require.NoError(t, rotatedKMS.AddKeyFile("master-1", keyPath))
+
+		decrypted, err := EnvelopeDecrypt(ctx, rotatedKMS, ciphertext)
+		require.NoError(t, err)
+		require.Equal(t, []byte("secret"), decrypted)
+	})
+}
@@ -7,5 +7,20 @@ var (
 	ErrRSAPublicKeyWrongType      = errors.New("RSA public key wrong type")
 	ErrRSAPrivateKeyWrongType     = errors.New("RSA private key wrong type")
 	ErrED25519PublicKeyWrongType  = errors.New("ed25519 public key wrong type")
+	ErrED25519PublicKeyWrongSize  = errors.New("ed25519 public key must be 32 bytes")
 	ErrED25519PrivateKeyWrongType = errors.New("ed25519 private key wrong type")
+	ErrECDSAPublicKeyWrongType    = errors.New("ECDSA public key wrong type")
+	ErrECDSAPrivateKeyWrongType   = errors.New("ECDSA private key wrong type")
+
+	ErrSignatureMissing    = errors.New("signature missing")
+	ErrSignatureInvalid    = errors.New("signature invalid")
+	ErrSignatureUnknownKey = errors.New("signature key unknown")
+	ErrSignatureExpired    = errors.New("signature timestamp outside allowed window")
+	ErrSignatureReplayed   = errors.New("signature nonce already used")
+	ErrSignatureAlgorithm  = errors.New("signature algorithm does not match key")
+
+	ErrMasterKeyWrongSize = errors.New("master key must be 32 bytes")
+	ErrMasterKeyNotFound  = errors.New("master key not found")
+	ErrEnvelopeHeader     = errors.New("invalid envelope header")
+	ErrEnvelopeCorrupted  = errors.New("envelope corrupted")
 )
@@ -0,0 +1,159 @@
+package crypto
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stepanbukhtii/easy-tools/cache"
+)
+
+var DefaultSignatureMaxSkew = 5 * time.Minute
+
+// HMACSecret is a shared secret identified by ID, the ID is sent with every signature
+// so the receiver can pick the right secret while several of them are active.
+type HMACSecret struct {
+	ID  string
+	Key []byte
+}
+
+// SignedRequest holds all parts of a request covered by the signature.
+type SignedRequest struct {
+	KeyID     string
+	Method    string
+	Path      string
+	Timestamp int64
+	Nonce     string
+	Body      []byte
+	Signature string
+}
+
+// Canonical returns the string to sign: key ID, method, path, unix timestamp, nonce
+// and hex encoded SHA-256 of the body, separated by new lines.
+func (r SignedRequest) Canonical() []byte {
+	bodyHash := sha256.Sum256(r.Body)
+
+	return []byte(strings.Join([]string{
+		r.KeyID,
+		strings.ToUpper(r.Method),
+		r.Path,
+		strconv.FormatInt(r.Timestamp, 10),
+		r.Nonce,
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n"))
+}
+
+type HMACSigner struct {
+	secret HMACSecret
+	hash   func() hash.Hash
+}
+
+func NewHMACSigner(secret HMACSecret) *HMACSigner {
+	return &HMACSigner{
+		secret: secret,
+		hash:   sha256.New,
+	}
+}
+
+// Sign signs the request with the current time and a random nonce.
+func (s *HMACSigner) Sign(method, path string, body []byte) (SignedRequest, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return SignedRequest{}, err
+	}
+
+	r := SignedRequest{
+		KeyID:     s.secret.ID,
+		Method:    method,
+		Path:      path,
+		Timestamp: time.Now().Unix(),
+		Nonce:     hex.EncodeToString(nonce),
+		Body:      body,
+	}
+	r.Signature = hex.EncodeToString(GetHMAC(s.hash, s.secret.Key, r.Canonical()))
+
+	return r, nil
+}
+
+type HMACVerifier struct {
+	secrets []HMACSecret
+	hash    func() hash.Hash
+	maxSkew time.Duration
+	nonces  cache.Cache[bool]
+}
+
+// NewHMACVerifier creates verifier accepting signatures made by any of the secrets.
+// When nonces is not nil every nonce is accepted only once, the cache TTL should be
+// at least twice maxSkew.
+func NewHMACVerifier(secrets []HMACSecret, maxSkew time.Duration, nonces cache.Cache[bool]) *HMACVerifier {
+	if maxSkew == 0 {
+		maxSkew = DefaultSignatureMaxSkew
+	}
+
+	return &HMACVerifier{
+		secrets: secrets,
+		hash:    sha256.New,
+		maxSkew: maxSkew,
+		nonces:  nonces,
+	}
+}
+
+func (v *HMACVerifier) Verify(ctx context.Context, r SignedRequest) error {
+	if r.Signature == "" {
+		return ErrSignatureMissing
+	}
+
+	signature, err := hex.DecodeString(r.Signature)
+	if err != nil {
+		return ErrSignatureInvalid
+	}
+
+	skew := time.Since(time.Unix(r.Timestamp, 0))
+	if skew > v.maxSkew || skew < -v.maxSkew {
+		return ErrSignatureExpired
+	}
+
+	var keyFound bool
+	var matched *HMACSecret
+	for i, secret := range v.secrets {
+		if r.KeyID != "" && secret.ID != r.KeyID {
+			continue
+		}
+		keyFound = true
+
+		if hmac.Equal(signature, GetHMAC(v.hash, secret.Key, r.Canonical())) {
+			matched = &v.secrets[i]
+			break
+		}
+	}
+
+	if !keyFound {
+		return ErrSignatureUnknownKey
+	}
+	if matched == nil {
+		return ErrSignatureInvalid
+	}
+
+	if v.nonces != nil {
+		if r.Nonce == "" {
+			return ErrSignatureInvalid
+		}
+
+		// nonce is stored under the secret which signed the request, not the sent key ID
+		ok, err := v.nonces.SetNX(ctx, matched.ID+":"+r.Nonce, true)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrSignatureReplayed
+		}
+	}
+
+	return nil
+}
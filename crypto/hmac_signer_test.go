@@ -0,0 +1,81 @@
+package crypto
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/stepanbukhtii/easy-tools/cache"
+)
+
+func TestHMACSigner(t *testing.T) {
+	ctx := context.Background()
+	oldSecret := HMACSecret{ID: "old", Key: []byte("old_secret")}
+	newSecret := HMACSecret{ID: "new", Key: []byte("new_secret")}
+	body := []byte(`{"event":"payment.succeeded"}`)
+
+	nonces := cache.NewMemory[bool](time.Hour, time.Minute)
+	defer nonces.Close()
+
+	verifier := NewHMACVerifier([]HMACSecret{newSecret, oldSecret}, time.Minute, nonces)
+
+	t.Run("rotated secrets", func(t *testing.T) {
+		for _, secret := range []HMACSecret{oldSecret, newSecret} {
+			signedRequest, err := NewHMACSigner(secret).Sign("post", "/webhook?id=1", body)
+			require.NoError(t, err)
+			require.NoError(t, verifier.Verify(ctx, signedRequest))
+		}
+	})
+
+	t.Run("replay", func(t *testing.T) {
+		signedRequest, err := NewHMACSigner(newSecret).Sign("POST", "/webhook", body)
+		require.NoError(t, err)
+		require.NoError(t, verifier.Verify(ctx, signedRequest))
+		require.ErrorIs(t, verifier.Verify(ctx, signedRequest), ErrSignatureReplayed)
+	})
+
+	t.Run("replay with other key ID", func(t *testing.T) {
+		signedRequest, err := NewHMACSigner(newSecret).Sign("POST", "/webhook", body)
+		require.NoError(t, err)
+		require.NoError(t, verifier.Verify(ctx, signedRequest))
+
+		stripped := signedRequest
+		stripped.KeyID = ""
+		require.ErrorIs(t, verifier.Verify(ctx, stripped), ErrSignatureInvalid)
+
+		changed := signedRequest
+		changed.KeyID = oldSecret.ID
+		require.ErrorIs(t, verifier.Verify(ctx, changed), ErrSignatureInvalid)
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		signedRequest, err := NewHMACSigner(newSecret).Sign("POST", "/webhook", body)
+		require.NoError(t, err)
+
+		signedRequest.Body = []byte(`{"event":"payment.refunded"}`)
+		require.ErrorIs(t, verifier.Verify(ctx, signedRequest), ErrSignatureInvalid)
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		signedRequest, err := NewHMACSigner(HMACSecret{ID: "unknown", Key: []byte("secret")}).Sign("POST", "/", nil)
+		require.NoError(t, err)
+		require.ErrorIs(t, verifier.Verify(ctx, signedRequest), ErrSignatureUnknownKey)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		signer := NewHMACSigner(newSecret)
+		signedRequest, err := signer.Sign("POST", "/webhook", body)
+		require.NoError(t, err)
+
+		signedRequest.Timestamp = time.Now().Add(-2 * time.Minute).Unix()
+		signedRequest.Signature = ""
+		require.ErrorIs(t, verifier.Verify(ctx, signedRequest), ErrSignatureMissing)
+
+		signedRequest, err = signer.Sign("POST", "/webhook", body)
+		require.NoError(t, err)
+		signedRequest.Timestamp = time.Now().Add(-2 * time.Minute).Unix()
+		require.ErrorIs(t, verifier.Verify(ctx, signedRequest), ErrSignatureExpired)
+	})
+}
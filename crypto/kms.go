@@ -0,0 +1,127 @@
+package crypto
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"os"
+	"strings"
+)
+
+// KMS wraps and unwraps data keys with a master key which never leaves the KMS.
+type KMS interface {
+	Encrypt(ctx context.Context, dataKey []byte) (WrappedKey, error)
+	Decrypt(ctx context.Context, wrappedKey WrappedKey) ([]byte, error)
+}
+
+// WrappedKey is a data key encrypted by the master key with ID KeyID.
+type WrappedKey struct {
+	KeyID      string
+	Ciphertext []byte
+}
+
+// LocalKMS is KMS with AES-256-GCM master keys held in memory. The first key is used
+// to wrap new data keys, the rest are kept to unwrap data keys of rotated master keys.
+type LocalKMS struct {
+	currentKeyID string
+	keys         map[string]cipher.AEAD
+}
+
+func NewLocalKMS(keyID string, masterKey []byte) (*LocalKMS, error) {
+	k := &LocalKMS{
+		currentKeyID: keyID,
+		keys:         make(map[string]cipher.AEAD),
+	}
+
+	if err := k.AddKey(keyID, masterKey); err != nil {
+		return nil, err
+	}
+
+	return k, nil
+}
+
+// NewLocalKMSFile reads base64 encoded 32 bytes master key from the file.
+func NewLocalKMSFile(keyID, path string) (*LocalKMS, error) {
+	masterKey, err := readMasterKeyFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewLocalKMS(keyID, masterKey)
+}
+
+// AddKey adds master key used only for unwrapping data keys.
+func (k *LocalKMS) AddKey(keyID string, masterKey []byte) error {
+	if len(masterKey) != 32 {
+		return ErrMasterKeyWrongSize
+	}
+
+	block, err := aes.NewCipher(masterKey)
+	if err != nil {
+		return err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return err
+	}
+
+	k.keys[keyID] = aead
+
+	return nil
+}
+
+// AddKeyFile reads base64 encoded master key from the file and adds it for unwrapping.
+func (k *LocalKMS) AddKeyFile(keyID, path string) error {
+	masterKey, err := readMasterKeyFile(path)
+	if err != nil {
+		return err
+	}
+
+	return k.AddKey(keyID, masterKey)
+}
+
+func (k *LocalKMS) Encrypt(_ context.Context, dataKey []byte) (WrappedKey, error) {
+	aead := k.keys[k.currentKeyID]
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return WrappedKey{}, err
+	}
+
+	return WrappedKey{
+		KeyID:      k.currentKeyID,
+		Ciphertext: aead.Seal(nonce, nonce, dataKey, []byte(k.currentKeyID)),
+	}, nil
+}
+
+func (k *LocalKMS) Decrypt(_ context.Context, wrappedKey WrappedKey) ([]byte, error) {
+	aead, ok := k.keys[wrappedKey.KeyID]
+	if !ok {
+		return nil, ErrMasterKeyNotFound
+	}
+
+	if len(wrappedKey.Ciphertext) < aead.NonceSize() {
+		return nil, ErrEnvelopeCorrupted
+	}
+
+	nonce, ciphertext := wrappedKey.Ciphertext[:aead.NonceSize()], wrappedKey.Ciphertext[aead.NonceSize():]
+
+	dataKey, err := aead.Open(nil, nonce, ciphertext, []byte(wrappedKey.KeyID))
+	if err != nil {
+		return nil, ErrEnvelopeCorrupted
+	}
+
+	return dataKey, nil
+}
+
+func readMasterKeyFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+}
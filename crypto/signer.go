@@ -0,0 +1,248 @@
+package crypto
+
+import (
+	stdcrypto "crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+type SignatureAlgorithm string
+
+const (
+	SignatureEd25519     SignatureAlgorithm = "Ed25519"
+	SignatureRSAPSS      SignatureAlgorithm = "RSA-PSS-SHA256"
+	SignatureRSAPKCS1v15 SignatureAlgorithm = "RSA-PKCS1v15-SHA256"
+	SignatureECDSA       SignatureAlgorithm = "ECDSA"
+)
+
+const rsaSignatureHash = stdcrypto.SHA256
+
+// Signer creates detached signatures of messages, the key ID is sent next to
+// the signature so receivers can pick the public key to verify it with.
+type Signer interface {
+	KeyID() string
+	Algorithm() SignatureAlgorithm
+	Sign(message []byte) ([]byte, error)
+}
+
+// Signature is detached signature with everything needed to verify it.
+type Signature struct {
+	KeyID     string
+	Algorithm SignatureAlgorithm
+	Value     []byte
+}
+
+func (s Signature) Base64() string {
+	return base64.StdEncoding.EncodeToString(s.Value)
+}
+
+func (s Signature) Hex() string {
+	return hex.EncodeToString(s.Value)
+}
+
+func Sign(signer Signer, message []byte) (Signature, error) {
+	value, err := signer.Sign(message)
+	if err != nil {
+		return Signature{}, err
+	}
+
+	return Signature{
+		KeyID:     signer.KeyID(),
+		Algorithm: signer.Algorithm(),
+		Value:     value,
+	}, nil
+}
+
+func DecodeSignatureBase64(signature string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(signature)
+}
+
+func DecodeSignatureHex(signature string) ([]byte, error) {
+	return hex.DecodeString(signature)
+}
+
+type signer struct {
+	keyID     string
+	algorithm SignatureAlgorithm
+	sign      func(message []byte) ([]byte, error)
+}
+
+func (s signer) KeyID() string                       { return s.keyID }
+func (s signer) Algorithm() SignatureAlgorithm       { return s.algorithm }
+func (s signer) Sign(message []byte) ([]byte, error) { return s.sign(message) }
+
+func NewEd25519Signer(keyID string, privateKey ed25519.PrivateKey) Signer {
+	return signer{
+		keyID:     keyID,
+		algorithm: SignatureEd25519,
+		sign: func(message []byte) ([]byte, error) {
+			return ed25519.Sign(privateKey, message), nil
+		},
+	}
+}
+
+func NewRSAPSSSigner(keyID string, privateKey *rsa.PrivateKey) Signer {
+	return signer{
+		keyID:     keyID,
+		algorithm: SignatureRSAPSS,
+		sign: func(message []byte) ([]byte, error) {
+			digest := digest(rsaSignatureHash, message)
+			return rsa.SignPSS(rand.Reader, privateKey, rsaSignatureHash, digest, nil)
+		},
+	}
+}
+
+func NewRSAPKCS1v15Signer(keyID string, privateKey *rsa.PrivateKey) Signer {
+	return signer{
+		keyID:     keyID,
+		algorithm: SignatureRSAPKCS1v15,
+		sign: func(message []byte) ([]byte, error) {
+			digest := digest(rsaSignatureHash, message)
+			return rsa.SignPKCS1v15(rand.Reader, privateKey, rsaSignatureHash, digest)
+		},
+	}
+}
+
+// NewECDSASigner creates ASN.1 encoded signatures, the hash is chosen by curve size:
+// SHA-256 for P-256, SHA-384 for P-384 and SHA-512 for P-521.
+func NewECDSASigner(keyID string, privateKey *ecdsa.PrivateKey) Signer {
+	return signer{
+		keyID:     keyID,
+		algorithm: SignatureECDSA,
+		sign: func(message []byte) ([]byte, error) {
+			return ecdsa.SignASN1(rand.Reader, privateKey, digest(ecdsaHash(privateKey.Curve), message))
+		},
+	}
+}
+
+type verificationKey struct {
+	algorithm SignatureAlgorithm
+	publicKey stdcrypto.PublicKey
+}
+
+// SignatureVerifier verifies detached signatures with a set of public keys by key ID.
+type SignatureVerifier struct {
+	keys map[string]verificationKey
+}
+
+func NewSignatureVerifier() *SignatureVerifier {
+	return &SignatureVerifier{keys: make(map[string]verificationKey)}
+}
+
+// AddKey adds public key, the key type must match the algorithm.
+func (v *SignatureVerifier) AddKey(keyID string, algorithm SignatureAlgorithm, publicKey stdcrypto.PublicKey) error {
+	var ok bool
+	switch algorithm {
+	case SignatureEd25519:
+		var edPublicKey ed25519.PublicKey
+		if edPublicKey, ok = publicKey.(ed25519.PublicKey); ok && len(edPublicKey) != ed25519.PublicKeySize {
+			return ErrED25519PublicKeyWrongSize
+		}
+	case SignatureRSAPSS, SignatureRSAPKCS1v15:
+		_, ok = publicKey.(*rsa.PublicKey)
+	case SignatureECDSA:
+		_, ok = publicKey.(*ecdsa.PublicKey)
+	}
+	if !ok {
+		return ErrSignatureAlgorithm
+	}
+
+	v.keys[keyID] = verificationKey{
+		algorithm: algorithm,
+		publicKey: publicKey,
+	}
+
+	return nil
+}
+
+func (v *SignatureVerifier) RemoveKey(keyID string) {
+	delete(v.keys, keyID)
+}
+
+func (v *SignatureVerifier) Verify(keyID string, message, signature []byte) error {
+	key, ok := v.keys[keyID]
+	if !ok {
+		return ErrSignatureUnknownKey
+	}
+
+	if len(signature) == 0 {
+		return ErrSignatureMissing
+	}
+
+	var valid bool
+	switch key.algorithm {
+	case SignatureEd25519:
+		valid = ed25519.Verify(key.publicKey.(ed25519.PublicKey), message, signature)
+	case SignatureRSAPSS:
+		digest := digest(rsaSignatureHash, message)
+		valid = rsa.VerifyPSS(key.publicKey.(*rsa.PublicKey), rsaSignatureHash, digest, signature, nil) == nil
+	case SignatureRSAPKCS1v15:
+		digest := digest(rsaSignatureHash, message)
+		valid = rsa.VerifyPKCS1v15(key.publicKey.(*rsa.PublicKey), rsaSignatureHash, digest, signature) == nil
+	case SignatureECDSA:
+		publicKey := key.publicKey.(*ecdsa.PublicKey)
+		valid = ecdsa.VerifyASN1(publicKey, digest(ecdsaHash(publicKey.Curve), message), signature)
+	}
+
+	if !valid {
+		return ErrSignatureInvalid
+	}
+
+	return nil
+}
+
+// VerifySignature verifies signature checking that its algorithm matches the key.
+func (v *SignatureVerifier) VerifySignature(message []byte, signature Signature) error {
+	key, ok := v.keys[signature.KeyID]
+	if !ok {
+		return ErrSignatureUnknownKey
+	}
+
+	if signature.Algorithm != "" && signature.Algorithm != key.algorithm {
+		return ErrSignatureAlgorithm
+	}
+
+	return v.Verify(signature.KeyID, message, signature.Value)
+}
+
+func (v *SignatureVerifier) VerifyBase64(keyID string, message []byte, signature string) error {
+	signatureBytes, err := DecodeSignatureBase64(signature)
+	if err != nil {
+		return ErrSignatureInvalid
+	}
+
+	return v.Verify(keyID, message, signatureBytes)
+}
+
+func (v *SignatureVerifier) VerifyHex(keyID string, message []byte, signature string) error {
+	signatureBytes, err := DecodeSignatureHex(signature)
+	if err != nil {
+		return ErrSignatureInvalid
+	}
+
+	return v.Verify(keyID, message, signatureBytes)
+}
+
+func ecdsaHash(curve elliptic.Curve) stdcrypto.Hash {
+	switch curve.Params().BitSize {
+	case 384:
+		return stdcrypto.SHA384
+	case 521:
+		return stdcrypto.SHA512
+	default:
+		return stdcrypto.SHA256
+	}
+}
+
+func digest(hash stdcrypto.Hash, message []byte) []byte {
+	h := hash.New()
+	h.Write(message)
+	return h.Sum(nil)
+}
@@ -0,0 +1,61 @@
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSigner(t *testing.T) {
+	message := []byte(`{"order_id":"123","amount":100}`)
+
+	edPublicKey, edPrivateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	rsaPrivateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	ecdsaPrivateKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	verifier := NewSignatureVerifier()
+	require.NoError(t, verifier.AddKey("ed", SignatureEd25519, edPublicKey))
+	require.NoError(t, verifier.AddKey("pss", SignatureRSAPSS, &rsaPrivateKey.PublicKey))
+	require.NoError(t, verifier.AddKey("pkcs", SignatureRSAPKCS1v15, &rsaPrivateKey.PublicKey))
+	require.NoError(t, verifier.AddKey("ecdsa", SignatureECDSA, &ecdsaPrivateKey.PublicKey))
+	require.ErrorIs(t, verifier.AddKey("wrong", SignatureECDSA, edPublicKey), ErrSignatureAlgorithm)
+	require.ErrorIs(t, verifier.AddKey("short", SignatureEd25519, edPublicKey[:16]), ErrED25519PublicKeyWrongSize)
+
+	signers := []Signer{
+		NewEd25519Signer("ed", edPrivateKey),
+		NewRSAPSSSigner("pss", rsaPrivateKey),
+		NewRSAPKCS1v15Signer("pkcs", rsaPrivateKey),
+		NewECDSASigner("ecdsa", ecdsaPrivateKey),
+	}
+
+	for _, signer := range signers {
+		t.Run(string(signer.Algorithm()), func(t *testing.T) {
+			signature, err := Sign(signer, message)
+			require.NoError(t, err)
+			require.Equal(t, signer.KeyID(), signature.KeyID)
+
+			require.NoError(t, verifier.VerifySignature(message, signature))
+			require.NoError(t, verifier.VerifyBase64(signature.KeyID, message, signature.Base64()))
+			require.NoError(t, verifier.VerifyHex(signature.KeyID, message, signature.Hex()))
+
+			require.ErrorIs(t, verifier.Verify(signature.KeyID, []byte("tampered"), signature.Value), ErrSignatureInvalid)
+			require.ErrorIs(t, verifier.Verify("unknown", message, signature.Value), ErrSignatureUnknownKey)
+		})
+	}
+
+	t.Run("algorithm mismatch", func(t *testing.T) {
+		signature, err := Sign(NewRSAPSSSigner("pkcs", rsaPrivateKey), message)
+		require.NoError(t, err)
+		require.ErrorIs(t, verifier.VerifySignature(message, signature), ErrSignatureAlgorithm)
+	})
+}
@@ -96,6 +96,15 @@ func SetRoles(ctx context.Context, roles []string) context.Context {
 	return SetClientInfo(ctx, client.Info{Roles: roles})
 }
 
+func SetScopes(ctx context.Context, scopes []string) context.Context {
+	clientInfo, ok := ctx.Value(clientInfoKey{}).(client.Info)
+	if ok {
+		clientInfo.Scopes = scopes
+		return SetClientInfo(ctx, clientInfo)
+	}
+	return SetClientInfo(ctx, client.Info{Scopes: scopes})
+}
+
 func SetSkipLogger(ctx context.Context, skipLogger bool) context.Context {
 	return context.WithValue(ctx, skipLoggerKey{}, skipLogger)
 }
@@ -0,0 +1,87 @@
+package ejwt
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var registeredClaimNames = []string{"iss", "sub", "aud", "exp", "nbf", "iat", "jti", "roles", "scope", "tid", "sid"}
+
+type ClaimsWithRoles struct {
+	jwt.RegisteredClaims
+	Roles []string `json:"roles"`
+}
+
+// Claims is full claims model, Extra holds any custom claims not covered by fields
+// and is flattened into the token payload.
+type Claims struct {
+	jwt.RegisteredClaims
+	Roles     []string       `json:"roles,omitempty"`
+	Scope     string         `json:"scope,omitempty"`
+	TenantID  string         `json:"tid,omitempty"`
+	SessionID string         `json:"sid,omitempty"`
+	Extra     map[string]any `json:"-"`
+}
+
+// Scopes returns OAuth scopes from space separated scope claim.
+func (c Claims) Scopes() []string {
+	return strings.Fields(c.Scope)
+}
+
+func (c Claims) HasRole(role string) bool {
+	return slices.Contains(c.Roles, role)
+}
+
+func (c Claims) HasScope(scope string) bool {
+	return slices.Contains(c.Scopes(), scope)
+}
+
+type claimsAlias Claims
+
+func (c Claims) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(claimsAlias(c))
+	if err != nil || len(c.Extra) == 0 {
+		return data, err
+	}
+
+	payload := make(map[string]any, len(c.Extra))
+	maps.Copy(payload, c.Extra)
+
+	// empty typed claims are omitted, so registered names in Extra would otherwise be issued as is
+	for _, name := range registeredClaimNames {
+		delete(payload, name)
+	}
+
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(payload)
+}
+
+func (c *Claims) UnmarshalJSON(data []byte) error {
+	var alias claimsAlias
+	if err := json.Unmarshal(data, &alias); err != nil {
+		return err
+	}
+
+	var payload map[string]any
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return err
+	}
+
+	for _, name := range registeredClaimNames {
+		delete(payload, name)
+	}
+
+	*c = Claims(alias)
+	if len(payload) > 0 {
+		c.Extra = payload
+	}
+
+	return nil
+}
@@ -0,0 +1,298 @@
+package ejwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	DefaultJWKSTTL                = time.Hour
+	DefaultJWKSMinRefreshInterval = time.Minute
+	DefaultJWKSTimeout            = 10 * time.Second
+)
+
+// JWK is JSON Web Key of a public key.
+type JWK struct {
+	KeyID     string `json:"kid,omitempty"`
+	KeyType   string `json:"kty"`
+	Use       string `json:"use,omitempty"`
+	Algorithm string `json:"alg,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	X         string `json:"x,omitempty"`
+	Y         string `json:"y,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// NewJWK encodes public key, used to publish own keys.
+func NewJWK(keyID string, publicKey crypto.PublicKey) (JWK, error) {
+	method, err := SigningMethodForKey(publicKey)
+	if err != nil {
+		return JWK{}, err
+	}
+
+	jwk := JWK{KeyID: keyID, Use: "sig", Algorithm: method.Alg()}
+
+	switch k := publicKey.(type) {
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(k)
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(k.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes())
+	case *ecdsa.PublicKey:
+		size := (k.Curve.Params().BitSize + 7) / 8
+		jwk.KeyType = "EC"
+		jwk.Curve = k.Curve.Params().Name
+		jwk.X = base64.RawURLEncoding.EncodeToString(k.X.FillBytes(make([]byte, size)))
+		jwk.Y = base64.RawURLEncoding.EncodeToString(k.Y.FillBytes(make([]byte, size)))
+	default:
+		return JWK{}, ErrUnsupportedKey
+	}
+
+	return jwk, nil
+}
+
+// PublicKey decodes RSA, EC and OKP Ed25519 keys.
+func (k JWK) PublicKey() (crypto.PublicKey, error) {
+	switch k.KeyType {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, ErrUnsupportedKey
+		}
+
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	case "OKP":
+		if k.Curve != "Ed25519" {
+			return nil, ErrUnsupportedKey
+		}
+
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, ErrUnsupportedKey
+		}
+
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, ErrUnsupportedKey
+	}
+}
+
+type JWKSOption func(s *JWKS)
+
+func WithJWKSHTTPClient(client *http.Client) JWKSOption {
+	return func(s *JWKS) { s.client = client }
+}
+
+// WithJWKSTTL sets keys TTL used when the response has no Cache-Control max-age.
+func WithJWKSTTL(ttl time.Duration) JWKSOption {
+	return func(s *JWKS) { s.ttl = ttl }
+}
+
+// WithJWKSMinRefreshInterval limits how often unknown kid triggers refetch.
+func WithJWKSMinRefreshInterval(interval time.Duration) JWKSOption {
+	return func(s *JWKS) { s.minRefreshInterval = interval }
+}
+
+// JWKS is KeySource fetching keys of identity provider from JWKS URL. Keys are cached
+// according to Cache-Control max-age and refetched on unknown kid at most once per
+// minimal refresh interval.
+type JWKS struct {
+	url                string
+	client             *http.Client
+	ttl                time.Duration
+	minRefreshInterval time.Duration
+
+	refreshMu   sync.Mutex
+	mu          sync.RWMutex
+	keys        map[string]crypto.PublicKey
+	expiresAt   time.Time
+	refreshedAt time.Time
+}
+
+func NewJWKS(url string, options ...JWKSOption) *JWKS {
+	s := &JWKS{
+		url:                url,
+		client:             &http.Client{Timeout: DefaultJWKSTimeout},
+		ttl:                DefaultJWKSTTL,
+		minRefreshInterval: DefaultJWKSMinRefreshInterval,
+		keys:               make(map[string]crypto.PublicKey),
+	}
+
+	for _, option := range options {
+		option(s)
+	}
+
+	return s
+}
+
+func (s *JWKS) PublicKey(keyID string) (crypto.PublicKey, error) {
+	publicKey, found, expired := s.cachedKey(keyID)
+	if found && !expired {
+		return publicKey, nil
+	}
+
+	if err := s.refresh(context.Background(), false); err != nil {
+		if found {
+			// provider is unavailable, keep using known key until it is back
+			return publicKey, nil
+		}
+		return nil, err
+	}
+
+	publicKey, found, _ = s.cachedKey(keyID)
+	if !found {
+		return nil, ErrKeyNotFound
+	}
+
+	return publicKey, nil
+}
+
+// Refresh fetches keys, can be used to warm up cache on start.
+func (s *JWKS) Refresh(ctx context.Context) error {
+	return s.refresh(ctx, true)
+}
+
+func (s *JWKS) cachedKey(keyID string) (crypto.PublicKey, bool, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	publicKey, found := s.keys[keyID]
+
+	return publicKey, found, time.Now().After(s.expiresAt)
+}
+
+func (s *JWKS) refresh(ctx context.Context, force bool) error {
+	s.refreshMu.Lock()
+	defer s.refreshMu.Unlock()
+
+	s.mu.RLock()
+	refreshedAt := s.refreshedAt
+	s.mu.RUnlock()
+
+	// also skips refresh done by concurrent caller while waiting for the lock
+	if !force && time.Since(refreshedAt) < s.minRefreshInterval {
+		return nil
+	}
+
+	keys, ttl, err := s.fetch(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.refreshedAt = time.Now()
+	if err != nil {
+		return err
+	}
+
+	s.keys = keys
+	s.expiresAt = s.refreshedAt.Add(ttl)
+
+	return nil
+}
+
+func (s *JWKS) fetch(ctx context.Context) (map[string]crypto.PublicKey, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("fetch jwks: unexpected status %d", resp.StatusCode)
+	}
+
+	var jwkSet JWKSet
+	if err := json.NewDecoder(resp.Body).Decode(&jwkSet); err != nil {
+		return nil, 0, err
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(jwkSet.Keys))
+	for _, jwk := range jwkSet.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		publicKey, err := jwk.PublicKey()
+		if err != nil {
+			continue
+		}
+
+		keys[jwk.KeyID] = publicKey
+	}
+
+	return keys, s.cacheTTL(resp.Header.Get("Cache-Control")), nil
+}
+
+func (s *JWKS) cacheTTL(cacheControl string) time.Duration {
+	for _, directive := range strings.Split(cacheControl, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+
+		switch strings.ToLower(name) {
+		case "no-store", "no-cache":
+			return 0
+		case "max-age":
+			seconds, err := strconv.Atoi(value)
+			if err == nil && seconds >= 0 {
+				return time.Duration(seconds) * time.Second
+			}
+		}
+	}
+
+	return s.ttl
+}
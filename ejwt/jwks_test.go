@@ -0,0 +1,161 @@
+package ejwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testProvider struct {
+	mu           sync.Mutex
+	keys         []JWK
+	cacheControl string
+	requests     atomic.Int32
+}
+
+func (p *testProvider) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	p.requests.Add(1)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.cacheControl != "" {
+		w.Header().Set("Cache-Control", p.cacheControl)
+	}
+	_ = json.NewEncoder(w).Encode(JWKSet{Keys: p.keys})
+}
+
+func (p *testProvider) addKey(t *testing.T, keyID string, signingKey SigningKey) JWTGenerator {
+	jwk, err := NewJWK(keyID, signingKey.Key.Public())
+	require.NoError(t, err)
+
+	p.mu.Lock()
+	p.keys = append(p.keys, jwk)
+	p.mu.Unlock()
+
+	signingKey.ID = keyID
+	jwtGenerator, err := NewJWTGeneratorKey(signingKey, "issuer", "audience", time.Minute)
+	require.NoError(t, err)
+
+	return jwtGenerator
+}
+
+func TestJWKS(t *testing.T) {
+	_, edPrivateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	rsaPrivateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	ecdsaPrivateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	t.Run("key types", func(t *testing.T) {
+		provider := &testProvider{}
+		server := httptest.NewServer(provider)
+		defer server.Close()
+
+		generators := []JWTGenerator{
+			provider.addKey(t, "ed", SigningKey{Key: edPrivateKey}),
+			provider.addKey(t, "rsa", SigningKey{Key: rsaPrivateKey}),
+			provider.addKey(t, "ecdsa", SigningKey{Key: ecdsaPrivateKey}),
+		}
+
+		verifier := NewVerifierKeySource(NewJWKS(server.URL), "issuer", "audience")
+
+		for _, jwtGenerator := range generators {
+			token, err := jwtGenerator.GenerateToken("user")
+			require.NoError(t, err)
+
+			_, err = verifier.VerifyClaims(token)
+			require.NoError(t, err)
+		}
+
+		require.Equal(t, int32(1), provider.requests.Load())
+	})
+
+	t.Run("unknown kid refresh is rate limited", func(t *testing.T) {
+		provider := &testProvider{cacheControl: "public, max-age=3600"}
+		server := httptest.NewServer(provider)
+		defer server.Close()
+
+		oldGenerator := provider.addKey(t, "old", SigningKey{Key: edPrivateKey})
+
+		jwks := NewJWKS(server.URL, WithJWKSMinRefreshInterval(100*time.Millisecond))
+		verifier := NewVerifierKeySource(jwks, "issuer", "audience")
+
+		token, err := oldGenerator.GenerateToken("user")
+		require.NoError(t, err)
+
+		_, err = verifier.VerifyClaims(token)
+		require.NoError(t, err)
+
+		newGenerator := provider.addKey(t, "new", SigningKey{Key: rsaPrivateKey})
+
+		token, err = newGenerator.GenerateToken("user")
+		require.NoError(t, err)
+
+		_, err = verifier.VerifyClaims(token)
+		require.ErrorIs(t, err, ErrKeyNotFound)
+		require.Equal(t, int32(1), provider.requests.Load())
+
+		time.Sleep(100 * time.Millisecond)
+
+		_, err = verifier.VerifyClaims(token)
+		require.NoError(t, err)
+		require.Equal(t, int32(2), provider.requests.Load())
+	})
+
+	t.Run("cache control", func(t *testing.T) {
+		provider := &testProvider{cacheControl: "no-cache"}
+		server := httptest.NewServer(provider)
+		defer server.Close()
+
+		jwtGenerator := provider.addKey(t, "ed", SigningKey{Key: edPrivateKey})
+
+		jwks := NewJWKS(server.URL, WithJWKSMinRefreshInterval(0))
+		verifier := NewVerifierKeySource(jwks, "issuer", "audience")
+
+		token, err := jwtGenerator.GenerateToken("user")
+		require.NoError(t, err)
+
+		for range 3 {
+			_, err = verifier.VerifyClaims(token)
+			require.NoError(t, err)
+		}
+
+		require.Equal(t, int32(3), provider.requests.Load())
+	})
+
+	t.Run("provider unavailable", func(t *testing.T) {
+		provider := &testProvider{cacheControl: "max-age=0"}
+		server := httptest.NewServer(provider)
+
+		jwtGenerator := provider.addKey(t, "ed", SigningKey{Key: edPrivateKey})
+
+		jwks := NewJWKS(server.URL, WithJWKSMinRefreshInterval(0))
+		verifier := NewVerifierKeySource(jwks, "issuer", "audience")
+
+		token, err := jwtGenerator.GenerateToken("user")
+		require.NoError(t, err)
+
+		_, err = verifier.VerifyClaims(token)
+		require.NoError(t, err)
+
+		server.Close()
+
+		_, err = verifier.VerifyClaims(token)
+		require.NoError(t, err)
+	})
+}
@@ -1,19 +1,20 @@
 package ejwt
 
 import (
+	"crypto"
 	"crypto/ed25519"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
-type ClaimsWithRoles struct {
-	jwt.RegisteredClaims
-	Roles []string `json:"roles"`
-}
-
 type JWTGenerator struct {
-	privateKey ed25519.PrivateKey
+	privateKey crypto.Signer
+	method     jwt.SigningMethod
+	keyID      string
 	issuer     string
 	audience   string
 	ttl        time.Duration
@@ -22,43 +23,118 @@ type JWTGenerator struct {
 func NewJWTGenerator(privateKey ed25519.PrivateKey, issuer, audience string, ttl time.Duration) JWTGenerator {
 	return JWTGenerator{
 		privateKey: privateKey,
+		method:     jwt.SigningMethodEdDSA,
 		issuer:     issuer,
 		audience:   audience,
 		ttl:        ttl,
 	}
 }
 
-func (g *JWTGenerator) GenerateToken(subject string) (string, error) {
-	now := time.Now().UTC()
-	claims := &jwt.RegisteredClaims{
-		Issuer:    g.issuer,
-		Subject:   subject,
-		Audience:  jwt.ClaimStrings{g.audience},
-		ExpiresAt: jwt.NewNumericDate(now.Add(g.ttl)),
-		NotBefore: jwt.NewNumericDate(now),
-		IssuedAt:  jwt.NewNumericDate(now),
+func NewJWTGeneratorKey(signingKey SigningKey, issuer, audience string, ttl time.Duration) (JWTGenerator, error) {
+	method := signingKey.Method
+	if method == nil {
+		var err error
+		if method, err = SigningMethodForKey(signingKey.Key); err != nil {
+			return JWTGenerator{}, err
+		}
 	}
 
-	t := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+	return JWTGenerator{
+		privateKey: signingKey.Key,
+		method:     method,
+		keyID:      signingKey.ID,
+		issuer:     issuer,
+		audience:   audience,
+		ttl:        ttl,
+	}, nil
+}
+
+type TokenOption func(c *Claims)
 
-	return t.SignedString(g.privateKey)
+// WithAudience replaces generator audience.
+func WithAudience(audience ...string) TokenOption {
+	return func(c *Claims) { c.Audience = audience }
 }
 
-func (g *JWTGenerator) GenerateTokenWthRoles(userID string, roles []string) (string, error) {
+// WithID replaces generated jti.
+func WithID(id string) TokenOption {
+	return func(c *Claims) { c.ID = id }
+}
+
+func WithTTL(ttl time.Duration) TokenOption {
+	return func(c *Claims) { c.ExpiresAt = jwt.NewNumericDate(c.IssuedAt.Add(ttl)) }
+}
+
+// WithNotBeforeSkew moves nbf to the past to tolerate clock skew between services.
+func WithNotBeforeSkew(skew time.Duration) TokenOption {
+	return func(c *Claims) { c.NotBefore = jwt.NewNumericDate(c.IssuedAt.Add(-skew)) }
+}
+
+func WithRoles(roles ...string) TokenOption {
+	return func(c *Claims) { c.Roles = roles }
+}
+
+func WithScopes(scopes ...string) TokenOption {
+	return func(c *Claims) { c.Scope = strings.Join(scopes, " ") }
+}
+
+func WithTenant(tenantID string) TokenOption {
+	return func(c *Claims) { c.TenantID = tenantID }
+}
+
+func WithSessionID(sessionID string) TokenOption {
+	return func(c *Claims) { c.SessionID = sessionID }
+}
+
+// WithExtra adds custom claim, registered claim names are ignored.
+func WithExtra(key string, value any) TokenOption {
+	return func(c *Claims) {
+		if slices.Contains(registeredClaimNames, key) {
+			return
+		}
+		if c.Extra == nil {
+			c.Extra = make(map[string]any)
+		}
+		c.Extra[key] = value
+	}
+}
+
+// NewClaims returns claims with issuer, audience, unique ID and timestamps set by generator.
+func (g *JWTGenerator) NewClaims(subject string, options ...TokenOption) Claims {
 	now := time.Now().UTC()
-	claims := &ClaimsWithRoles{
+	claims := Claims{
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
 			Issuer:    g.issuer,
-			Subject:   userID,
+			Subject:   subject,
 			Audience:  jwt.ClaimStrings{g.audience},
 			ExpiresAt: jwt.NewNumericDate(now.Add(g.ttl)),
 			NotBefore: jwt.NewNumericDate(now),
 			IssuedAt:  jwt.NewNumericDate(now),
 		},
-		Roles: roles,
 	}
 
-	t := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+	for _, option := range options {
+		option(&claims)
+	}
+
+	return claims
+}
+
+func (g *JWTGenerator) GenerateToken(subject string, options ...TokenOption) (string, error) {
+	return GenerateTokenClaims(g, g.NewClaims(subject, options...))
+}
+
+func (g *JWTGenerator) GenerateTokenWthRoles(userID string, roles []string) (string, error) {
+	return g.GenerateToken(userID, WithRoles(roles...))
+}
+
+// GenerateTokenClaims signs any claims type, registered claims are used as is.
+func GenerateTokenClaims[C jwt.Claims](g *JWTGenerator, claims C) (string, error) {
+	t := jwt.NewWithClaims(g.method, claims)
+	if g.keyID != "" {
+		t.Header["kid"] = g.keyID
+	}
 
 	return t.SignedString(g.privateKey)
 }
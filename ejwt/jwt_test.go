@@ -2,6 +2,7 @@ package ejwt
 
 import (
 	"crypto/ed25519"
+	"encoding/json"
 	"testing"
 	"time"
 
@@ -52,3 +53,100 @@ func TestGenerateToken(t *testing.T) {
 	assert.True(t, parsedToken.Valid)
 	assert.Equal(t, roles, claimsWithRoles.Roles)
 }
+
+func TestGenerateTokenOptions(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+
+	jwtGenerator := NewJWTGenerator(privateKey, "issuer", "audience", time.Minute)
+
+	token, err := jwtGenerator.GenerateToken("user",
+		WithAudience("billing", "orders"),
+		WithID("token-id"),
+		WithNotBeforeSkew(10*time.Second),
+		WithRoles("admin"),
+		WithScopes("orders:read", "orders:write"),
+		WithTenant("tenant"),
+		WithSessionID("session"),
+		WithExtra("plan", "pro"),
+		WithExtra("sub", "ignored"),
+	)
+	assert.NoError(t, err)
+
+	verifier := NewVerifier(publicKey, "issuer", "orders")
+
+	claims, err := verifier.VerifyClaims(token)
+	assert.NoError(t, err)
+	assert.Equal(t, "user", claims.Subject)
+	assert.Equal(t, jwt.ClaimStrings{"billing", "orders"}, claims.Audience)
+	assert.Equal(t, "token-id", claims.ID)
+	assert.Equal(t, time.Now().Add(-10*time.Second).Unix(), claims.NotBefore.Unix())
+	assert.Equal(t, []string{"admin"}, claims.Roles)
+	assert.Equal(t, []string{"orders:read", "orders:write"}, claims.Scopes())
+	assert.True(t, claims.HasScope("orders:write"))
+	assert.Equal(t, "tenant", claims.TenantID)
+	assert.Equal(t, "session", claims.SessionID)
+	assert.Equal(t, map[string]any{"plan": "pro"}, claims.Extra)
+
+	token, err = jwtGenerator.GenerateToken("user")
+	assert.NoError(t, err)
+
+	claims, err = verifier.VerifyClaims(token)
+	assert.Error(t, err)
+
+	claims, err = NewVerifier(publicKey, "issuer", "audience").VerifyClaims(token)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, claims.ID)
+}
+
+func TestClaimsExtraRegisteredNames(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+
+	jwtGenerator := NewJWTGenerator(privateKey, "issuer", "audience", time.Minute)
+
+	token, err := jwtGenerator.GenerateToken("user",
+		WithExtra("roles", []string{"admin"}),
+		WithExtra("tid", "tenant"),
+	)
+	assert.NoError(t, err)
+
+	claims, err := NewVerifier(publicKey, "issuer", "audience").VerifyClaims(token)
+	assert.NoError(t, err)
+	assert.Empty(t, claims.Roles)
+	assert.Empty(t, claims.TenantID)
+	assert.Nil(t, claims.Extra)
+
+	data, err := json.Marshal(Claims{Extra: map[string]any{
+		"roles": []string{"admin"},
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"sub":   "admin",
+		"plan":  "pro",
+	}})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"plan":"pro"}`, string(data))
+}
+
+type customClaims struct {
+	jwt.RegisteredClaims
+	Email string `json:"email"`
+}
+
+func TestGenerateTokenClaims(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+
+	jwtGenerator := NewJWTGenerator(privateKey, "issuer", "audience", time.Minute)
+	registeredClaims := jwtGenerator.NewClaims("user").RegisteredClaims
+
+	token, err := GenerateTokenClaims(&jwtGenerator, customClaims{
+		RegisteredClaims: registeredClaims,
+		Email:            "user@example.com",
+	})
+	assert.NoError(t, err)
+
+	var claims customClaims
+	assert.NoError(t, NewVerifier(publicKey, "issuer", "audience").Verify(token, &claims))
+	assert.Equal(t, "user@example.com", claims.Email)
+	assert.Equal(t, registeredClaims.ID, claims.ID)
+}
@@ -0,0 +1,115 @@
+package ejwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"errors"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrUnsupportedKey = errors.New("unsupported key type")
+	ErrKeyNotFound    = errors.New("key not found")
+)
+
+// DefaultValidMethods are signing methods accepted by Verifier, a token is verified
+// only when its method matches the type of the key found by kid.
+var DefaultValidMethods = []string{
+	jwt.SigningMethodEdDSA.Alg(),
+	jwt.SigningMethodRS256.Alg(),
+	jwt.SigningMethodRS384.Alg(),
+	jwt.SigningMethodRS512.Alg(),
+	jwt.SigningMethodPS256.Alg(),
+	jwt.SigningMethodPS384.Alg(),
+	jwt.SigningMethodPS512.Alg(),
+	jwt.SigningMethodES256.Alg(),
+	jwt.SigningMethodES384.Alg(),
+	jwt.SigningMethodES512.Alg(),
+}
+
+// SigningKey is private key used by JWTGenerator, ID is set as kid header.
+// When Method is nil it is chosen by key type: EdDSA, RS256 or ES256/ES384/ES512 by curve.
+type SigningKey struct {
+	ID     string
+	Key    crypto.Signer
+	Method jwt.SigningMethod
+}
+
+// SigningMethodForKey returns default signing method for private or public key.
+func SigningMethodForKey(key any) (jwt.SigningMethod, error) {
+	switch k := key.(type) {
+	case ed25519.PrivateKey, ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA, nil
+	case *rsa.PrivateKey, *rsa.PublicKey:
+		return jwt.SigningMethodRS256, nil
+	case *ecdsa.PrivateKey:
+		return ecdsaSigningMethod(k.Curve.Params().BitSize), nil
+	case *ecdsa.PublicKey:
+		return ecdsaSigningMethod(k.Curve.Params().BitSize), nil
+	default:
+		return nil, ErrUnsupportedKey
+	}
+}
+
+func ecdsaSigningMethod(bitSize int) jwt.SigningMethod {
+	switch bitSize {
+	case 384:
+		return jwt.SigningMethodES384
+	case 521:
+		return jwt.SigningMethodES512
+	default:
+		return jwt.SigningMethodES256
+	}
+}
+
+// KeySource resolves verification key by kid header, kid is empty for tokens without it.
+type KeySource interface {
+	PublicKey(keyID string) (crypto.PublicKey, error)
+}
+
+// KeySet is static KeySource, several keys with overlapping validity allow rotation
+// without invalidating tokens signed by the previous key.
+type KeySet struct {
+	mu   sync.RWMutex
+	keys map[string]crypto.PublicKey
+}
+
+func NewKeySet() *KeySet {
+	return &KeySet{keys: make(map[string]crypto.PublicKey)}
+}
+
+func (s *KeySet) Add(keyID string, publicKey crypto.PublicKey) error {
+	if _, err := SigningMethodForKey(publicKey); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys[keyID] = publicKey
+
+	return nil
+}
+
+func (s *KeySet) Remove(keyID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.keys, keyID)
+}
+
+func (s *KeySet) PublicKey(keyID string) (crypto.PublicKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	publicKey, ok := s.keys[keyID]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+
+	return publicKey, nil
+}
@@ -0,0 +1,100 @@
+package ejwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeySet(t *testing.T) {
+	edPublicKey, edPrivateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	rsaPrivateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	ecdsaPrivateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	keys := NewKeySet()
+	require.NoError(t, keys.Add("ed", edPublicKey))
+	require.NoError(t, keys.Add("rsa", &rsaPrivateKey.PublicKey))
+	require.NoError(t, keys.Add("ecdsa", &ecdsaPrivateKey.PublicKey))
+	require.ErrorIs(t, keys.Add("unsupported", "key"), ErrUnsupportedKey)
+
+	verifier := NewVerifierKeySource(keys, "issuer", "audience")
+
+	tests := []struct {
+		name       string
+		signingKey SigningKey
+		alg        string
+	}{
+		{
+			name:       "EdDSA",
+			signingKey: SigningKey{ID: "ed", Key: edPrivateKey},
+			alg:        jwt.SigningMethodEdDSA.Alg(),
+		}, {
+			name:       "RS256",
+			signingKey: SigningKey{ID: "rsa", Key: rsaPrivateKey},
+			alg:        jwt.SigningMethodRS256.Alg(),
+		}, {
+			name:       "PS256",
+			signingKey: SigningKey{ID: "rsa", Key: rsaPrivateKey, Method: jwt.SigningMethodPS256},
+			alg:        jwt.SigningMethodPS256.Alg(),
+		}, {
+			name:       "ES256",
+			signingKey: SigningKey{ID: "ecdsa", Key: ecdsaPrivateKey},
+			alg:        jwt.SigningMethodES256.Alg(),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			jwtGenerator, err := NewJWTGeneratorKey(test.signingKey, "issuer", "audience", time.Minute)
+			require.NoError(t, err)
+
+			token, err := jwtGenerator.GenerateToken("user")
+			require.NoError(t, err)
+
+			parsedToken, _, err := jwt.NewParser().ParseUnverified(token, &Claims{})
+			require.NoError(t, err)
+			require.Equal(t, test.alg, parsedToken.Header["alg"])
+			require.Equal(t, test.signingKey.ID, parsedToken.Header["kid"])
+
+			claims, err := verifier.VerifyClaims(token)
+			require.NoError(t, err)
+			require.Equal(t, "user", claims.Subject)
+		})
+	}
+
+	t.Run("key of other type under kid", func(t *testing.T) {
+		jwtGenerator, err := NewJWTGeneratorKey(SigningKey{ID: "ed", Key: rsaPrivateKey}, "issuer", "audience", time.Minute)
+		require.NoError(t, err)
+
+		token, err := jwtGenerator.GenerateToken("user")
+		require.NoError(t, err)
+
+		_, err = verifier.VerifyClaims(token)
+		require.Error(t, err)
+	})
+
+	t.Run("rotation", func(t *testing.T) {
+		jwtGenerator, err := NewJWTGeneratorKey(SigningKey{ID: "ed", Key: edPrivateKey}, "issuer", "audience", time.Minute)
+		require.NoError(t, err)
+
+		token, err := jwtGenerator.GenerateToken("user")
+		require.NoError(t, err)
+
+		keys.Remove("ed")
+
+		_, err = verifier.VerifyClaims(token)
+		require.ErrorIs(t, err, ErrKeyNotFound)
+	})
+}
@@ -0,0 +1,60 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const WellKnownPath = "/.well-known/openid-configuration"
+
+var ErrIssuerMismatch = errors.New("discovery issuer does not match")
+
+// Metadata is OpenID Provider metadata from discovery document.
+type Metadata struct {
+	Issuer                           string   `json:"issuer"`
+	AuthorizationEndpoint            string   `json:"authorization_endpoint"`
+	TokenEndpoint                    string   `json:"token_endpoint"`
+	UserInfoEndpoint                 string   `json:"userinfo_endpoint,omitempty"`
+	EndSessionEndpoint               string   `json:"end_session_endpoint,omitempty"`
+	JWKSURI                          string   `json:"jwks_uri"`
+	ScopesSupported                  []string `json:"scopes_supported,omitempty"`
+	ResponseTypesSupported           []string `json:"response_types_supported,omitempty"`
+	IDTokenSigningAlgValuesSupported []string `json:"id_token_signing_alg_values_supported,omitempty"`
+	ClaimsSupported                  []string `json:"claims_supported,omitempty"`
+}
+
+// Discover fetches discovery document of the issuer and checks it belongs to the issuer.
+func Discover(ctx context.Context, client *http.Client, issuer string) (Metadata, error) {
+	var metadata Metadata
+
+	url := strings.TrimSuffix(issuer, "/") + WellKnownPath
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return metadata, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return metadata, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return metadata, fmt.Errorf("fetch openid configuration: unexpected status %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&metadata); err != nil {
+		return metadata, err
+	}
+
+	if strings.TrimSuffix(metadata.Issuer, "/") != strings.TrimSuffix(issuer, "/") {
+		return metadata, ErrIssuerMismatch
+	}
+
+	return metadata, nil
+}
@@ -0,0 +1,90 @@
+package oidc
+
+import (
+	stdcrypto "crypto"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/stepanbukhtii/easy-tools/rest/client"
+)
+
+var (
+	ErrNonceMismatch           = errors.New("id token nonce mismatch")
+	ErrAuthorizedPartyMismatch = errors.New("id token azp mismatch")
+	ErrAccessTokenHashMismatch = errors.New("id token at_hash mismatch")
+)
+
+// IDTokenClaims are ID token claims with standard profile, email and groups claims.
+type IDTokenClaims struct {
+	jwt.RegisteredClaims
+	Nonce             string   `json:"nonce,omitempty"`
+	AuthorizedParty   string   `json:"azp,omitempty"`
+	AccessTokenHash   string   `json:"at_hash,omitempty"`
+	Email             string   `json:"email,omitempty"`
+	EmailVerified     bool     `json:"email_verified,omitempty"`
+	Name              string   `json:"name,omitempty"`
+	GivenName         string   `json:"given_name,omitempty"`
+	FamilyName        string   `json:"family_name,omitempty"`
+	PreferredUsername string   `json:"preferred_username,omitempty"`
+	Locale            string   `json:"locale,omitempty"`
+	Groups            []string `json:"groups,omitempty"`
+}
+
+// ClientInfo maps claims into client info, groups become roles.
+func (c IDTokenClaims) ClientInfo(info client.Info) client.Info {
+	info.Subject = c.Subject
+	info.Email = c.Email
+	info.Name = c.Name
+	if c.Groups != nil {
+		info.Roles = c.Groups
+	}
+	if c.Locale != "" {
+		info.Locale = c.Locale
+	}
+	return info
+}
+
+func (c IDTokenClaims) validate(clientID, nonce string) error {
+	if len(c.Audience) > 1 && c.AuthorizedParty == "" {
+		return ErrAuthorizedPartyMismatch
+	}
+
+	if c.AuthorizedParty != "" && c.AuthorizedParty != clientID {
+		return ErrAuthorizedPartyMismatch
+	}
+
+	if nonce != "" && subtle.ConstantTimeCompare([]byte(c.Nonce), []byte(nonce)) != 1 {
+		return ErrNonceMismatch
+	}
+
+	return nil
+}
+
+// AccessTokenHash returns at_hash value: base64url of the left half of access token hash,
+// the hash is chosen by ID token signing algorithm.
+func AccessTokenHash(alg, accessToken string) (string, error) {
+	var hash stdcrypto.Hash
+	switch {
+	case alg == jwt.SigningMethodEdDSA.Alg(), strings.HasSuffix(alg, "512"):
+		hash = stdcrypto.SHA512
+	case strings.HasSuffix(alg, "384"):
+		hash = stdcrypto.SHA384
+	case slices.Contains([]string{"RS256", "PS256", "ES256", "HS256"}, alg):
+		hash = stdcrypto.SHA256
+	default:
+		return "", jwt.ErrTokenSignatureInvalid
+	}
+
+	h := hash.New()
+	h.Write([]byte(accessToken))
+	sum := h.Sum(nil)
+
+	return base64.RawURLEncoding.EncodeToString(sum[:len(sum)/2]), nil
+}
@@ -0,0 +1,148 @@
+package oidc
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/stepanbukhtii/easy-tools/ejwt"
+)
+
+var DefaultScopes = []string{"openid", "profile", "email"}
+
+type options struct {
+	client          *http.Client
+	verifierOptions []ejwt.VerifierOption
+	jwksOptions     []ejwt.JWKSOption
+}
+
+type Option func(o *options)
+
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *options) { o.client = client }
+}
+
+func WithVerifierOptions(verifierOptions ...ejwt.VerifierOption) Option {
+	return func(o *options) { o.verifierOptions = append(o.verifierOptions, verifierOptions...) }
+}
+
+func WithJWKSOptions(jwksOptions ...ejwt.JWKSOption) Option {
+	return func(o *options) { o.jwksOptions = append(o.jwksOptions, jwksOptions...) }
+}
+
+// Provider validates ID tokens issued by OpenID provider to the client.
+type Provider struct {
+	metadata Metadata
+	clientID string
+	jwks     *ejwt.JWKS
+	verifier *ejwt.Verifier
+}
+
+// NewProvider discovers provider metadata of the issuer.
+func NewProvider(ctx context.Context, issuer, clientID string, opts ...Option) (*Provider, error) {
+	o := options{client: &http.Client{Timeout: ejwt.DefaultJWKSTimeout}}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	metadata, err := Discover(ctx, o.client, issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewProviderMetadata(metadata, clientID, opts...), nil
+}
+
+// NewProviderMetadata creates provider from already known metadata.
+func NewProviderMetadata(metadata Metadata, clientID string, opts ...Option) *Provider {
+	o := options{client: &http.Client{Timeout: ejwt.DefaultJWKSTimeout}}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	jwks := ejwt.NewJWKS(metadata.JWKSURI, append([]ejwt.JWKSOption{ejwt.WithJWKSHTTPClient(o.client)}, o.jwksOptions...)...)
+
+	verifierOptions := o.verifierOptions
+	validMethods := slices.DeleteFunc(slices.Clone(metadata.IDTokenSigningAlgValuesSupported), func(alg string) bool {
+		return !slices.Contains(ejwt.DefaultValidMethods, alg)
+	})
+	if len(validMethods) > 0 {
+		verifierOptions = append([]ejwt.VerifierOption{ejwt.WithValidMethods(validMethods...)}, verifierOptions...)
+	}
+
+	return &Provider{
+		metadata: metadata,
+		clientID: clientID,
+		jwks:     jwks,
+		verifier: ejwt.NewVerifierKeySource(jwks, metadata.Issuer, clientID, verifierOptions...),
+	}
+}
+
+func (p *Provider) Metadata() Metadata {
+	return p.metadata
+}
+
+// KeySource returns provider keys, used to verify provider access tokens with ejwt.Verifier.
+func (p *Provider) KeySource() ejwt.KeySource {
+	return p.jwks
+}
+
+// AuthCodeURL returns authorization endpoint URL starting authorization code flow.
+func (p *Provider) AuthCodeURL(redirectURI, state, nonce string, scopes ...string) string {
+	if len(scopes) == 0 {
+		scopes = DefaultScopes
+	}
+
+	params := url.Values{
+		"response_type": {"code"},
+		"client_id":     {p.clientID},
+		"redirect_uri":  {redirectURI},
+		"scope":         {strings.Join(scopes, " ")},
+		"state":         {state},
+	}
+	if nonce != "" {
+		params.Set("nonce", nonce)
+	}
+
+	separator := "?"
+	if strings.Contains(p.metadata.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+
+	return p.metadata.AuthorizationEndpoint + separator + params.Encode()
+}
+
+// VerifyIDToken validates signature, issuer, audience, expiration, azp and nonce sent in
+// authorization request. When access token is issued together with ID token its at_hash is checked.
+func (p *Provider) VerifyIDToken(_ context.Context, rawIDToken, nonce, accessToken string) (IDTokenClaims, error) {
+	var claims IDTokenClaims
+	if err := p.verifier.Verify(rawIDToken, &claims); err != nil {
+		return claims, err
+	}
+
+	if err := claims.validate(p.clientID, nonce); err != nil {
+		return claims, err
+	}
+
+	if accessToken != "" && claims.AccessTokenHash != "" {
+		token, _, err := jwt.NewParser().ParseUnverified(rawIDToken, &jwt.MapClaims{})
+		if err != nil {
+			return claims, err
+		}
+
+		accessTokenHash, err := AccessTokenHash(token.Method.Alg(), accessToken)
+		if err != nil {
+			return claims, err
+		}
+
+		if accessTokenHash != claims.AccessTokenHash {
+			return claims, ErrAccessTokenHashMismatch
+		}
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,169 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stepanbukhtii/easy-tools/ejwt"
+	"github.com/stepanbukhtii/easy-tools/rest/client"
+)
+
+const (
+	testClientID = "client"
+	testNonce    = "nonce"
+)
+
+func TestProvider(t *testing.T) {
+	ctx := context.Background()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	jwk, err := ejwt.NewJWK("key", &privateKey.PublicKey)
+	require.NoError(t, err)
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	mux.HandleFunc(WellKnownPath, func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(Metadata{
+			Issuer:                           server.URL,
+			AuthorizationEndpoint:            server.URL + "/authorize",
+			TokenEndpoint:                    server.URL + "/token",
+			JWKSURI:                          server.URL + "/jwks",
+			IDTokenSigningAlgValuesSupported: []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(ejwt.JWKSet{Keys: []ejwt.JWK{jwk}})
+	})
+
+	provider, err := NewProvider(ctx, server.URL, testClientID)
+	require.NoError(t, err)
+
+	accessToken := "access_token"
+	accessTokenHash, err := AccessTokenHash("RS256", accessToken)
+	require.NoError(t, err)
+
+	newClaims := func() IDTokenClaims {
+		now := time.Now()
+		return IDTokenClaims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    server.URL,
+				Subject:   "user",
+				Audience:  jwt.ClaimStrings{testClientID},
+				ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+				IssuedAt:  jwt.NewNumericDate(now),
+			},
+			Nonce:           testNonce,
+			AccessTokenHash: accessTokenHash,
+			Email:           "user@example.com",
+			Name:            "User Name",
+			Groups:          []string{"admin"},
+		}
+	}
+
+	sign := func(method jwt.SigningMethod, claims IDTokenClaims) string {
+		token := jwt.NewWithClaims(method, claims)
+		token.Header["kid"] = "key"
+		signed, err := token.SignedString(privateKey)
+		require.NoError(t, err)
+		return signed
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		claims, err := provider.VerifyIDToken(ctx, sign(jwt.SigningMethodRS256, newClaims()), testNonce, accessToken)
+		require.NoError(t, err)
+
+		info := claims.ClientInfo(client.Info{Locale: client.LocaleEN, IPAddress: "127.0.0.1"})
+		require.Equal(t, client.Info{
+			Subject:   "user",
+			Email:     "user@example.com",
+			Name:      "User Name",
+			Roles:     []string{"admin"},
+			Locale:    client.LocaleEN,
+			IPAddress: "127.0.0.1",
+		}, info)
+	})
+
+	tests := []struct {
+		name        string
+		method      jwt.SigningMethod
+		modify      func(c *IDTokenClaims)
+		accessToken string
+		expectedErr error
+	}{
+		{
+			name:        "nonce",
+			method:      jwt.SigningMethodRS256,
+			modify:      func(c *IDTokenClaims) { c.Nonce = "other" },
+			expectedErr: ErrNonceMismatch,
+		}, {
+			name:   "azp required for several audiences",
+			method: jwt.SigningMethodRS256,
+			modify: func(c *IDTokenClaims) {
+				c.Audience = jwt.ClaimStrings{testClientID, "other"}
+			},
+			expectedErr: ErrAuthorizedPartyMismatch,
+		}, {
+			name:        "azp other client",
+			method:      jwt.SigningMethodRS256,
+			modify:      func(c *IDTokenClaims) { c.AuthorizedParty = "other" },
+			expectedErr: ErrAuthorizedPartyMismatch,
+		}, {
+			name:        "at_hash",
+			method:      jwt.SigningMethodRS256,
+			modify:      func(c *IDTokenClaims) {},
+			accessToken: "other_access_token",
+			expectedErr: ErrAccessTokenHashMismatch,
+		}, {
+			name:        "audience",
+			method:      jwt.SigningMethodRS256,
+			modify:      func(c *IDTokenClaims) { c.Audience = jwt.ClaimStrings{"other"} },
+			expectedErr: jwt.ErrTokenInvalidAudience,
+		}, {
+			name:        "algorithm not supported by provider",
+			method:      jwt.SigningMethodPS256,
+			modify:      func(c *IDTokenClaims) {},
+			expectedErr: jwt.ErrTokenSignatureInvalid,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			claims := newClaims()
+			test.modify(&claims)
+
+			_, err := provider.VerifyIDToken(ctx, sign(test.method, claims), testNonce, test.accessToken)
+			require.ErrorIs(t, err, test.expectedErr)
+		})
+	}
+
+	t.Run("auth code url", func(t *testing.T) {
+		authURL, err := url.Parse(provider.AuthCodeURL("https://app/callback", "state", testNonce))
+		require.NoError(t, err)
+		require.Equal(t, server.URL+"/authorize", authURL.Scheme+"://"+authURL.Host+authURL.Path)
+		require.Equal(t, "openid profile email", authURL.Query().Get("scope"))
+		require.Equal(t, testNonce, authURL.Query().Get("nonce"))
+	})
+
+	t.Run("issuer mismatch", func(t *testing.T) {
+		mux.HandleFunc("/other"+WellKnownPath, func(w http.ResponseWriter, _ *http.Request) {
+			_ = json.NewEncoder(w).Encode(Metadata{Issuer: server.URL})
+		})
+
+		_, err := NewProvider(ctx, server.URL+"/other", testClientID)
+		require.ErrorIs(t, err, ErrIssuerMismatch)
+	})
+}
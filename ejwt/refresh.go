@@ -0,0 +1,246 @@
+package ejwt
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+
+	"github.com/stepanbukhtii/easy-tools/cache"
+)
+
+const RefreshTokenAudience = "refresh"
+
+var (
+	ErrRefreshTokenInvalid = errors.New("refresh token invalid")
+	ErrRefreshTokenExpired = errors.New("refresh token expired")
+	ErrRefreshTokenReused  = errors.New("refresh token reused")
+	ErrRefreshTokenRevoked = errors.New("refresh token family revoked")
+)
+
+// RefreshSession is stored for every issued refresh token, it keeps access token claims
+// to issue the next pair and the family ID shared by all tokens rotated from one login.
+type RefreshSession struct {
+	FamilyID  string         `json:"family_id"`
+	Subject   string         `json:"subject"`
+	Roles     []string       `json:"roles,omitempty"`
+	Scope     string         `json:"scope,omitempty"`
+	TenantID  string         `json:"tenant_id,omitempty"`
+	Extra     map[string]any `json:"extra,omitempty"`
+	ExpiresAt time.Time      `json:"expires_at"`
+}
+
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresAt    time.Time
+	SessionID    string
+}
+
+type RefreshOption func(m *RefreshManager)
+
+// WithJWTRefreshTokens issues refresh tokens as JWT with RefreshTokenAudience signed by
+// the generator key instead of opaque random strings.
+func WithJWTRefreshTokens() RefreshOption {
+	return func(m *RefreshManager) {
+		keys := NewKeySet()
+		_ = keys.Add(m.generator.keyID, m.generator.privateKey.Public())
+
+		m.verifier = NewVerifierKeySource(keys, m.generator.issuer, RefreshTokenAudience,
+			WithValidMethods(m.generator.method.Alg()),
+		)
+	}
+}
+
+// RefreshManager issues access and refresh token pairs and rotates refresh token on every use.
+// Presenting already used refresh token revokes all tokens of its family.
+// Store TTL must be not less than refresh token TTL, it bounds reuse detection window.
+type RefreshManager struct {
+	generator *JWTGenerator
+	store     cache.Cache[RefreshSession]
+	ttl       time.Duration
+	verifier  *Verifier
+}
+
+func NewRefreshManager(
+	generator *JWTGenerator,
+	store cache.Cache[RefreshSession],
+	ttl time.Duration,
+	options ...RefreshOption,
+) *RefreshManager {
+	m := &RefreshManager{
+		generator: generator,
+		store:     store,
+		ttl:       ttl,
+	}
+
+	for _, option := range options {
+		option(m)
+	}
+
+	return m
+}
+
+// Issue starts new refresh token family, used on login.
+func (m *RefreshManager) Issue(ctx context.Context, subject string, options ...TokenOption) (TokenPair, error) {
+	claims := m.generator.NewClaims(subject, options...)
+
+	session := RefreshSession{
+		FamilyID: uuid.NewString(),
+		Subject:  subject,
+		Roles:    claims.Roles,
+		Scope:    claims.Scope,
+		TenantID: claims.TenantID,
+		Extra:    claims.Extra,
+	}
+
+	return m.issue(ctx, session)
+}
+
+// Refresh exchanges refresh token for a new pair, the presented token can not be used again.
+func (m *RefreshManager) Refresh(ctx context.Context, refreshToken string) (TokenPair, error) {
+	tokenID, err := m.tokenID(refreshToken)
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	session, err := m.store.Get(ctx, refreshKey(tokenID))
+	if err != nil {
+		if errors.Is(err, cache.ErrNotFound) {
+			return TokenPair{}, ErrRefreshTokenInvalid
+		}
+		return TokenPair{}, err
+	}
+
+	revoked, err := m.store.Exists(ctx, revokedKey(session.FamilyID))
+	if err != nil {
+		return TokenPair{}, err
+	}
+	if revoked {
+		return TokenPair{}, ErrRefreshTokenRevoked
+	}
+
+	firstUse, err := m.store.SetNX(ctx, usedKey(tokenID), session)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	if !firstUse {
+		if err := m.RevokeFamily(ctx, session.FamilyID); err != nil {
+			return TokenPair{}, err
+		}
+		return TokenPair{}, ErrRefreshTokenReused
+	}
+
+	if time.Now().After(session.ExpiresAt) {
+		return TokenPair{}, ErrRefreshTokenExpired
+	}
+
+	return m.issue(ctx, session)
+}
+
+// RevokeFamily revokes all refresh tokens issued for the session, used on logout.
+func (m *RefreshManager) RevokeFamily(ctx context.Context, familyID string) error {
+	return m.store.Set(ctx, revokedKey(familyID), RefreshSession{FamilyID: familyID})
+}
+
+func (m *RefreshManager) issue(ctx context.Context, session RefreshSession) (TokenPair, error) {
+	options := []TokenOption{
+		WithRoles(session.Roles...),
+		WithScopes(strings.Fields(session.Scope)...),
+		WithTenant(session.TenantID),
+		WithSessionID(session.FamilyID),
+	}
+	for key, value := range session.Extra {
+		options = append(options, WithExtra(key, value))
+	}
+
+	claims := m.generator.NewClaims(session.Subject, options...)
+
+	accessToken, err := GenerateTokenClaims(m.generator, claims)
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	session.ExpiresAt = time.Now().Add(m.ttl)
+
+	refreshToken, tokenID, err := m.newRefreshToken(session)
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	if err := m.store.Set(ctx, refreshKey(tokenID), session); err != nil {
+		return TokenPair{}, err
+	}
+
+	return TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresAt:    claims.ExpiresAt.Time,
+		SessionID:    session.FamilyID,
+	}, nil
+}
+
+func (m *RefreshManager) newRefreshToken(session RefreshSession) (string, string, error) {
+	if m.verifier != nil {
+		tokenID := uuid.NewString()
+		claims := m.generator.NewClaims(session.Subject,
+			WithID(tokenID),
+			WithAudience(RefreshTokenAudience),
+			WithSessionID(session.FamilyID),
+			WithTTL(m.ttl),
+		)
+
+		refreshToken, err := GenerateTokenClaims(m.generator, claims)
+		return refreshToken, tokenID, err
+	}
+
+	data := make([]byte, 32)
+	if _, err := rand.Read(data); err != nil {
+		return "", "", err
+	}
+
+	refreshToken := base64.RawURLEncoding.EncodeToString(data)
+
+	return refreshToken, opaqueTokenID(refreshToken), nil
+}
+
+func (m *RefreshManager) tokenID(refreshToken string) (string, error) {
+	if m.verifier == nil {
+		return opaqueTokenID(refreshToken), nil
+	}
+
+	var claims Claims
+	if err := m.verifier.Verify(refreshToken, &claims); err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return "", ErrRefreshTokenExpired
+		}
+		return "", ErrRefreshTokenInvalid
+	}
+
+	return claims.ID, nil
+}
+
+// opaqueTokenID hashes opaque token, so the store never holds usable tokens.
+func opaqueTokenID(refreshToken string) string {
+	hash := sha256.Sum256([]byte(refreshToken))
+	return hex.EncodeToString(hash[:])
+}
+
+func refreshKey(tokenID string) string {
+	return "refresh:" + tokenID
+}
+
+func usedKey(tokenID string) string {
+	return "used:" + tokenID
+}
+
+func revokedKey(familyID string) string {
+	return "revoked:" + familyID
+}
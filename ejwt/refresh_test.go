@@ -0,0 +1,98 @@
+package ejwt
+
+import (
+	"context"
+	"crypto/ed25519"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/stepanbukhtii/easy-tools/cache"
+)
+
+func TestRefreshManager(t *testing.T) {
+	ctx := context.Background()
+
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	jwtGenerator := NewJWTGenerator(privateKey, "issuer", "audience", time.Minute)
+	verifier := NewVerifier(publicKey, "issuer", "audience")
+
+	tests := []struct {
+		name    string
+		options []RefreshOption
+	}{
+		{
+			name: "opaque",
+		}, {
+			name:    "jwt",
+			options: []RefreshOption{WithJWTRefreshTokens()},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store := cache.NewMemory[RefreshSession](time.Hour, time.Minute)
+			defer store.Close()
+
+			manager := NewRefreshManager(&jwtGenerator, store, time.Hour, test.options...)
+
+			pair, err := manager.Issue(ctx, "user", WithRoles("admin"), WithTenant("tenant"))
+			require.NoError(t, err)
+
+			_, err = verifier.VerifyClaims(pair.RefreshToken)
+			require.Error(t, err, "refresh token must not be accepted as access token")
+
+			rotated, err := manager.Refresh(ctx, pair.RefreshToken)
+			require.NoError(t, err)
+			require.NotEqual(t, pair.RefreshToken, rotated.RefreshToken)
+			require.Equal(t, pair.SessionID, rotated.SessionID)
+
+			claims, err := verifier.VerifyClaims(rotated.AccessToken)
+			require.NoError(t, err)
+			require.Equal(t, "user", claims.Subject)
+			require.Equal(t, []string{"admin"}, claims.Roles)
+			require.Equal(t, "tenant", claims.TenantID)
+			require.Equal(t, pair.SessionID, claims.SessionID)
+
+			_, err = manager.Refresh(ctx, pair.RefreshToken)
+			require.ErrorIs(t, err, ErrRefreshTokenReused)
+
+			_, err = manager.Refresh(ctx, rotated.RefreshToken)
+			require.ErrorIs(t, err, ErrRefreshTokenRevoked)
+
+			_, err = manager.Refresh(ctx, "unknown")
+			require.ErrorIs(t, err, ErrRefreshTokenInvalid)
+		})
+	}
+
+	t.Run("revoke family", func(t *testing.T) {
+		store := cache.NewMemory[RefreshSession](time.Hour, time.Minute)
+		defer store.Close()
+
+		manager := NewRefreshManager(&jwtGenerator, store, time.Hour)
+
+		pair, err := manager.Issue(ctx, "user")
+		require.NoError(t, err)
+
+		require.NoError(t, manager.RevokeFamily(ctx, pair.SessionID))
+
+		_, err = manager.Refresh(ctx, pair.RefreshToken)
+		require.ErrorIs(t, err, ErrRefreshTokenRevoked)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		store := cache.NewMemory[RefreshSession](time.Hour, time.Minute)
+		defer store.Close()
+
+		manager := NewRefreshManager(&jwtGenerator, store, -time.Second)
+
+		pair, err := manager.Issue(ctx, "user")
+		require.NoError(t, err)
+
+		_, err = manager.Refresh(ctx, pair.RefreshToken)
+		require.ErrorIs(t, err, ErrRefreshTokenExpired)
+	})
+}
@@ -0,0 +1,87 @@
+package ejwt
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/stepanbukhtii/easy-tools/cache"
+)
+
+var (
+	ErrTokenRevoked   = errors.New("token revoked")
+	ErrTokenIDMissing = errors.New("token ID missing")
+	// ErrRevocationCheck wraps revocation store errors, the token itself may be valid.
+	ErrRevocationCheck = errors.New("token revocation check failed")
+)
+
+// RevocationStore keeps revoked tokens until they expire.
+type RevocationStore interface {
+	// Revoke revokes single token by jti.
+	Revoke(ctx context.Context, claims Claims) error
+	// RevokeSubject revokes all tokens of the subject issued until now.
+	RevokeSubject(ctx context.Context, subject string) error
+	IsRevoked(ctx context.Context, claims Claims) (bool, error)
+}
+
+// CacheRevocationStore is RevocationStore on cache.Memory or cache.Redis. Revoked jti is kept
+// for the remaining token lifetime, revoked subject is kept for maxTokenTTL.
+type CacheRevocationStore struct {
+	cache       cache.TTLCache[int64]
+	maxTokenTTL time.Duration
+}
+
+func NewCacheRevocationStore(c cache.TTLCache[int64], maxTokenTTL time.Duration) *CacheRevocationStore {
+	return &CacheRevocationStore{
+		cache:       c,
+		maxTokenTTL: maxTokenTTL,
+	}
+}
+
+func (s *CacheRevocationStore) Revoke(ctx context.Context, claims Claims) error {
+	if claims.ID == "" {
+		return ErrTokenIDMissing
+	}
+
+	ttl := s.maxTokenTTL
+	if claims.ExpiresAt != nil {
+		ttl = time.Until(claims.ExpiresAt.Time)
+	}
+
+	if ttl <= 0 {
+		return nil
+	}
+
+	return s.cache.SetTTL(ctx, tokenRevocationKey(claims.ID), time.Now().Unix(), ttl)
+}
+
+func (s *CacheRevocationStore) RevokeSubject(ctx context.Context, subject string) error {
+	return s.cache.SetTTL(ctx, subjectRevocationKey(subject), time.Now().Unix(), s.maxTokenTTL)
+}
+
+func (s *CacheRevocationStore) IsRevoked(ctx context.Context, claims Claims) (bool, error) {
+	if claims.ID != "" {
+		revoked, err := s.cache.Exists(ctx, tokenRevocationKey(claims.ID))
+		if err != nil || revoked {
+			return revoked, err
+		}
+	}
+
+	revokedAt, err := s.cache.Get(ctx, subjectRevocationKey(claims.Subject))
+	if err != nil {
+		if errors.Is(err, cache.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return claims.IssuedAt == nil || claims.IssuedAt.Unix() <= revokedAt, nil
+}
+
+func tokenRevocationKey(tokenID string) string {
+	return "jti:" + tokenID
+}
+
+func subjectRevocationKey(subject string) string {
+	return "sub:" + subject
+}
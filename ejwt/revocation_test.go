@@ -0,0 +1,67 @@
+package ejwt
+
+import (
+	"context"
+	"crypto/ed25519"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/stepanbukhtii/easy-tools/cache"
+)
+
+func TestRevocationStore(t *testing.T) {
+	ctx := context.Background()
+
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	jwtGenerator := NewJWTGenerator(privateKey, "issuer", "audience", time.Minute)
+
+	revocationCache := cache.NewMemory[int64](time.Hour, time.Minute)
+	defer revocationCache.Close()
+
+	store := NewCacheRevocationStore(revocationCache, time.Minute)
+	verifier := NewVerifier(publicKey, "issuer", "audience", WithRevocationStore(store))
+
+	t.Run("token", func(t *testing.T) {
+		token, err := jwtGenerator.GenerateToken("user")
+		require.NoError(t, err)
+
+		otherToken, err := jwtGenerator.GenerateToken("user")
+		require.NoError(t, err)
+
+		claims, err := verifier.VerifyClaimsContext(ctx, token)
+		require.NoError(t, err)
+
+		require.NoError(t, store.Revoke(ctx, claims))
+
+		_, err = verifier.VerifyClaimsContext(ctx, token)
+		require.ErrorIs(t, err, ErrTokenRevoked)
+
+		_, err = verifier.VerifyClaimsContext(ctx, otherToken)
+		require.NoError(t, err)
+	})
+
+	t.Run("subject", func(t *testing.T) {
+		token, err := jwtGenerator.GenerateToken("locked_user")
+		require.NoError(t, err)
+
+		require.NoError(t, store.RevokeSubject(ctx, "locked_user"))
+
+		_, err = verifier.VerifyClaimsContext(ctx, token)
+		require.ErrorIs(t, err, ErrTokenRevoked)
+
+		claims := jwtGenerator.NewClaims("locked_user")
+		claims.IssuedAt.Time = claims.IssuedAt.Add(time.Second)
+
+		revoked, err := store.IsRevoked(ctx, claims)
+		require.NoError(t, err)
+		require.False(t, revoked)
+	})
+
+	t.Run("without jti", func(t *testing.T) {
+		require.ErrorIs(t, store.Revoke(ctx, Claims{}), ErrTokenIDMissing)
+	})
+}
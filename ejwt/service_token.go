@@ -0,0 +1,82 @@
+package ejwt
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultServiceTokenRefreshBefore is how long before expiry a cached service token is replaced.
+const DefaultServiceTokenRefreshBefore = 30 * time.Second
+
+// TokenSource provides bearer tokens for calls to the audience service.
+type TokenSource interface {
+	Token(ctx context.Context, audience string) (string, error)
+}
+
+type ServiceTokenOption func(s *ServiceTokenSource)
+
+// WithServiceTokenRefreshBefore replaces DefaultServiceTokenRefreshBefore.
+func WithServiceTokenRefreshBefore(d time.Duration) ServiceTokenOption {
+	return func(s *ServiceTokenSource) { s.refreshBefore = d }
+}
+
+// WithServiceTokenOptions adds token options to every minted token, e.g. WithTTL or WithRoles.
+func WithServiceTokenOptions(options ...TokenOption) ServiceTokenOption {
+	return func(s *ServiceTokenSource) { s.options = append(s.options, options...) }
+}
+
+type serviceToken struct {
+	token     string
+	expiresAt time.Time
+}
+
+// ServiceTokenSource mints short-lived tokens for service to service calls where no
+// user token is available, subject is usually config.Service.Name. Tokens are cached
+// per audience until they are close to expiry.
+type ServiceTokenSource struct {
+	generator     *JWTGenerator
+	subject       string
+	refreshBefore time.Duration
+	options       []TokenOption
+
+	mu     sync.Mutex
+	tokens map[string]serviceToken
+}
+
+func NewServiceTokenSource(generator *JWTGenerator, subject string, opts ...ServiceTokenOption) *ServiceTokenSource {
+	s := &ServiceTokenSource{
+		generator:     generator,
+		subject:       subject,
+		refreshBefore: DefaultServiceTokenRefreshBefore,
+		tokens:        make(map[string]serviceToken),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Token returns cached token for the audience or mints a new one.
+func (s *ServiceTokenSource) Token(_ context.Context, audience string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if cached, ok := s.tokens[audience]; ok && time.Until(cached.expiresAt) > s.refreshBefore {
+		return cached.token, nil
+	}
+
+	options := append(s.options[:len(s.options):len(s.options)], WithAudience(audience))
+	claims := s.generator.NewClaims(s.subject, options...)
+
+	token, err := GenerateTokenClaims(s.generator, claims)
+	if err != nil {
+		return "", err
+	}
+
+	s.tokens[audience] = serviceToken{token: token, expiresAt: claims.ExpiresAt.Time}
+
+	return token, nil
+}
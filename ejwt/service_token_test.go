@@ -0,0 +1,45 @@
+package ejwt
+
+import (
+	"context"
+	"crypto/ed25519"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceTokenSource(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	generator := NewJWTGenerator(privateKey, "issuer", "", time.Minute)
+	source := NewServiceTokenSource(&generator, "orders", WithServiceTokenOptions(WithRoles("service")))
+
+	ctx := context.Background()
+
+	token, err := source.Token(ctx, "payments")
+	require.NoError(t, err)
+
+	claims, err := NewVerifier(publicKey, "issuer", "payments").VerifyClaims(token)
+	require.NoError(t, err)
+	require.Equal(t, "orders", claims.Subject)
+	require.Equal(t, []string{"service"}, claims.Roles)
+
+	cached, err := source.Token(ctx, "payments")
+	require.NoError(t, err)
+	require.Equal(t, token, cached)
+
+	other, err := source.Token(ctx, "users")
+	require.NoError(t, err)
+	require.NotEqual(t, token, other)
+
+	expiring := NewServiceTokenSource(&generator, "orders", WithServiceTokenRefreshBefore(time.Minute))
+
+	first, err := expiring.Token(ctx, "payments")
+	require.NoError(t, err)
+
+	second, err := expiring.Token(ctx, "payments")
+	require.NoError(t, err)
+	require.NotEqual(t, first, second)
+}
@@ -0,0 +1,148 @@
+package ejwt
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrEmptySubject = errors.New("token subject is empty")
+)
+
+type verifierOptions struct {
+	revocation         RevocationStore
+	validMethods       []string
+	expirationOptional bool
+	leeway             time.Duration
+	parserOptions      []jwt.ParserOption
+}
+
+type VerifierOption func(o *verifierOptions)
+
+// WithExpirationOptional accepts tokens without exp claim, exp is still validated when present.
+func WithExpirationOptional() VerifierOption {
+	return func(o *verifierOptions) { o.expirationOptional = true }
+}
+
+// WithRevocationStore rejects revoked tokens with ErrTokenRevoked.
+func WithRevocationStore(store RevocationStore) VerifierOption {
+	return func(o *verifierOptions) { o.revocation = store }
+}
+
+// WithValidMethods replaces DefaultValidMethods.
+func WithValidMethods(methods ...string) VerifierOption {
+	return func(o *verifierOptions) { o.validMethods = methods }
+}
+
+// WithLeeway tolerates clock skew when validating exp, nbf and iat.
+func WithLeeway(leeway time.Duration) VerifierOption {
+	return func(o *verifierOptions) { o.leeway = leeway }
+}
+
+func WithParserOptions(options ...jwt.ParserOption) VerifierOption {
+	return func(o *verifierOptions) { o.parserOptions = append(o.parserOptions, options...) }
+}
+
+// Verifier validates tokens issued by JWTGenerator, shared by REST and gRPC auth.
+type Verifier struct {
+	keys       KeySource
+	parser     *jwt.Parser
+	revocation RevocationStore
+}
+
+// NewVerifier creates verifier of EdDSA tokens signed by single key without kid.
+func NewVerifier(publicKey ed25519.PublicKey, issuer, audience string, options ...VerifierOption) *Verifier {
+	keys := NewKeySet()
+	_ = keys.Add("", publicKey)
+
+	options = append([]VerifierOption{WithValidMethods(jwt.SigningMethodEdDSA.Alg())}, options...)
+
+	return NewVerifierKeySource(keys, issuer, audience, options...)
+}
+
+// NewVerifierKeySource creates verifier resolving keys by kid header.
+func NewVerifierKeySource(keys KeySource, issuer, audience string, options ...VerifierOption) *Verifier {
+	o := verifierOptions{validMethods: DefaultValidMethods}
+	for _, option := range options {
+		option(&o)
+	}
+
+	parserOptions := []jwt.ParserOption{
+		jwt.WithValidMethods(o.validMethods),
+		jwt.WithIssuer(issuer),
+		jwt.WithAudience(audience),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(o.leeway),
+	}
+
+	if !o.expirationOptional {
+		parserOptions = append(parserOptions, jwt.WithExpirationRequired())
+	}
+
+	return &Verifier{
+		keys:       keys,
+		parser:     jwt.NewParser(append(parserOptions, o.parserOptions...)...),
+		revocation: o.revocation,
+	}
+}
+
+// Verify parses token into claims, checks signature and registered claims.
+func (v *Verifier) Verify(tokenString string, claims jwt.Claims) error {
+	token, err := v.parser.ParseWithClaims(tokenString, claims, v.keyFunc)
+	if err != nil {
+		return err
+	}
+
+	if !token.Valid {
+		return ErrInvalidToken
+	}
+
+	return nil
+}
+
+func (v *Verifier) keyFunc(token *jwt.Token) (any, error) {
+	keyID, _ := token.Header["kid"].(string)
+	return v.keys.PublicKey(keyID)
+}
+
+// ParseUnverified parses token into claims without any validation.
+func (v *Verifier) ParseUnverified(tokenString string, claims jwt.Claims) error {
+	_, _, err := v.parser.ParseUnverified(tokenString, claims)
+	return err
+}
+
+// VerifyClaims verifies token and returns claims with not empty subject.
+func (v *Verifier) VerifyClaims(tokenString string) (Claims, error) {
+	return v.VerifyClaimsContext(context.Background(), tokenString)
+}
+
+// VerifyClaimsContext is VerifyClaims also checking revocation store when it is set, store
+// errors are wrapped with ErrRevocationCheck.
+func (v *Verifier) VerifyClaimsContext(ctx context.Context, tokenString string) (Claims, error) {
+	var claims Claims
+	if err := v.Verify(tokenString, &claims); err != nil {
+		return claims, err
+	}
+
+	if claims.Subject == "" {
+		return claims, ErrEmptySubject
+	}
+
+	if v.revocation != nil {
+		revoked, err := v.revocation.IsRevoked(ctx, claims)
+		if err != nil {
+			return claims, fmt.Errorf("%w: %w", ErrRevocationCheck, err)
+		}
+		if revoked {
+			return claims, ErrTokenRevoked
+		}
+	}
+
+	return claims, nil
+}
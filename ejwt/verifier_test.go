@@ -0,0 +1,77 @@
+package ejwt
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+var errRevocationStoreDown = errors.New("revocation store down")
+
+type failingRevocationStore struct{}
+
+func (failingRevocationStore) Revoke(context.Context, Claims) error { return errRevocationStoreDown }
+
+func (failingRevocationStore) RevokeSubject(context.Context, string) error {
+	return errRevocationStoreDown
+}
+
+func (failingRevocationStore) IsRevoked(context.Context, Claims) (bool, error) {
+	return false, errRevocationStoreDown
+}
+
+func TestVerifier(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	jwtGenerator := NewJWTGenerator(privateKey, "issuer", "audience", time.Minute)
+
+	t.Run("expired", func(t *testing.T) {
+		token, err := jwtGenerator.GenerateToken("user", WithTTL(-time.Second))
+		require.NoError(t, err)
+
+		_, err = NewVerifier(publicKey, "issuer", "audience").VerifyClaims(token)
+		require.ErrorIs(t, err, jwt.ErrTokenExpired)
+
+		_, err = NewVerifier(publicKey, "issuer", "audience", WithLeeway(time.Minute)).VerifyClaims(token)
+		require.NoError(t, err)
+	})
+
+	t.Run("expiration optional", func(t *testing.T) {
+		claims := jwtGenerator.NewClaims("user")
+		claims.ExpiresAt = nil
+
+		token, err := GenerateTokenClaims(&jwtGenerator, claims)
+		require.NoError(t, err)
+
+		_, err = NewVerifier(publicKey, "issuer", "audience").VerifyClaims(token)
+		require.ErrorIs(t, err, jwt.ErrTokenRequiredClaimMissing)
+
+		_, err = NewVerifier(publicKey, "issuer", "audience", WithExpirationOptional()).VerifyClaims(token)
+		require.NoError(t, err)
+	})
+
+	t.Run("revocation store failed", func(t *testing.T) {
+		token, err := jwtGenerator.GenerateToken("user")
+		require.NoError(t, err)
+
+		verifier := NewVerifier(publicKey, "issuer", "audience", WithRevocationStore(failingRevocationStore{}))
+		_, err = verifier.VerifyClaims(token)
+		require.ErrorIs(t, err, ErrRevocationCheck)
+		require.ErrorIs(t, err, errRevocationStoreDown)
+		require.NotErrorIs(t, err, ErrTokenRevoked)
+	})
+
+	t.Run("empty subject", func(t *testing.T) {
+		token, err := jwtGenerator.GenerateToken("")
+		require.NoError(t, err)
+
+		_, err = NewVerifier(publicKey, "issuer", "audience").VerifyClaims(token)
+		require.ErrorIs(t, err, ErrEmptySubject)
+	})
+}
@@ -8,6 +8,14 @@ const (
 	MessagingMessageBodyContent = "messaging.message.body.content"
 	MessagingOperationDuration  = "messaging.operation.duration"
 
-	RPCRequestBodyContent = "rpc.request.body.content"
-	RPCRequestDuration    = "rpc.request.duration"
+	RPCRequestBodyContent  = "rpc.request.body.content"
+	RPCResponseBodyContent = "rpc.response.body.content"
+	RPCRequestDuration     = "rpc.request.duration"
+	RPCDeadlineBudget      = "rpc.request.deadline.budget"
+	RPCDeadlineConsumed    = "rpc.request.deadline.consumed"
+
+	RPCStreamMessagesSent     = "rpc.stream.messages.sent"
+	RPCStreamMessagesReceived = "rpc.stream.messages.received"
+	RPCStreamBytesSent        = "rpc.stream.bytes.sent"
+	RPCStreamBytesReceived    = "rpc.stream.bytes.received"
 )
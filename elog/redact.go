@@ -0,0 +1,412 @@
+package elog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// RedactedValue replaces redacted values.
+const RedactedValue = "[REDACTED]"
+
+// RedactTag is the struct tag marking fields to redact: `log:"redact"`.
+const RedactTag = "log"
+
+var (
+	// DefaultMaxBodySize limits logged payloads in bytes.
+	DefaultMaxBodySize = 8 * 1024
+
+	// DefaultRedactFields are matched case-insensitively ignoring "_" and "-", so
+	// "card_number" also redacts "cardNumber" and "Card-Number".
+	DefaultRedactFields = []string{
+		"password",
+		"secret",
+		"token",
+		"access_token",
+		"refresh_token",
+		"id_token",
+		"authorization",
+		"api_key",
+		"card_number",
+		"cvv",
+		"cvc",
+		"pin",
+	}
+
+	// CardNumberPattern matches 13 to 19 digit candidates with optional spaces or dashes,
+	// only candidates passing the Luhn check are masked.
+	CardNumberPattern = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+
+	// DefaultRedactor is used by the gRPC, gin, resty and messaging loggers.
+	DefaultRedactor = NewRedactor(
+		WithRedactFields(DefaultRedactFields...),
+		WithRedactPatternFunc(CardNumberPattern, RedactedValue, ValidCardNumber),
+		WithMaxSize(DefaultMaxBodySize),
+	)
+)
+
+type redactPattern struct {
+	re          *regexp.Regexp
+	replacement string
+	match       func(s string) bool
+}
+
+type RedactorOption func(r *Redactor)
+
+// WithRedactFields redacts values of object fields with the names.
+func WithRedactFields(names ...string) RedactorOption {
+	return func(r *Redactor) {
+		for _, name := range names {
+			r.fields[normalizeField(name)] = struct{}{}
+		}
+	}
+}
+
+// WithRedactPattern replaces matches in string values, or in the whole payload when it is
+// not JSON.
+func WithRedactPattern(re *regexp.Regexp, replacement string) RedactorOption {
+	return func(r *Redactor) {
+		r.patterns = append(r.patterns, redactPattern{re: re, replacement: replacement})
+	}
+}
+
+// WithRedactPatternFunc replaces only matches accepted by match, e.g. ValidCardNumber.
+func WithRedactPatternFunc(re *regexp.Regexp, replacement string, match func(s string) bool) RedactorOption {
+	return func(r *Redactor) {
+		r.patterns = append(r.patterns, redactPattern{re: re, replacement: replacement, match: match})
+	}
+}
+
+// WithMaxSize truncates payloads longer than size bytes, zero disables truncation.
+func WithMaxSize(size int) RedactorOption {
+	return func(r *Redactor) { r.maxSize = size }
+}
+
+// Redactor removes sensitive values from logged payloads by field names, `log:"redact"`
+// struct tags, protobuf debug_redact field options and regex masks, then truncates them.
+type Redactor struct {
+	fields   map[string]struct{}
+	patterns []redactPattern
+	maxSize  int
+
+	typeFields sync.Map // reflect.Type or protoreflect.FullName to *taggedFields
+}
+
+func NewRedactor(opts ...RedactorOption) *Redactor {
+	r := &Redactor{fields: make(map[string]struct{})}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// JSON redacts raw payload, JSON is redacted by fields and patterns, other content by patterns.
+func (r *Redactor) JSON(data []byte) string {
+	return r.redact(data, nil)
+}
+
+// Value redacts value marshaled to JSON, protobuf messages are marshaled with protojson,
+// raw []byte, string and json.RawMessage are redacted as JSON.
+func (r *Redactor) Value(v any) string {
+	switch value := v.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return r.JSON(value)
+	case json.RawMessage:
+		return r.JSON(value)
+	case string:
+		return r.JSON([]byte(value))
+	case proto.Message:
+		data, err := protojson.Marshal(value)
+		if err != nil {
+			return r.Truncate(fmt.Sprintf("%v", v))
+		}
+		return r.redact(data, r.valueFields(v))
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return r.Truncate(fmt.Sprintf("%v", v))
+	}
+
+	return r.redact(data, r.valueFields(v))
+}
+
+// Truncate cuts s to the max size keeping valid UTF-8.
+func (r *Redactor) Truncate(s string) string {
+	if r.maxSize <= 0 || len(s) <= r.maxSize {
+		return s
+	}
+
+	cut := r.maxSize
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+
+	return fmt.Sprintf("%s...[truncated %d bytes]", s[:cut], len(s)-cut)
+}
+
+// taggedFields mirrors JSON shape of a type down to fields marked for redaction, so only
+// the marked field is redacted and not every field with the same name.
+type taggedFields struct {
+	redact bool
+	// fields of object by normalized name
+	fields map[string]*taggedFields
+	// values of map object with any keys
+	values *taggedFields
+}
+
+func (t *taggedFields) field(name string) *taggedFields {
+	if t == nil {
+		return nil
+	}
+	if t.values != nil {
+		return t.values
+	}
+	return t.fields[normalizeField(name)]
+}
+
+func (r *Redactor) redact(data []byte, tagged *taggedFields) string {
+	if len(data) == 0 {
+		return ""
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var tree any
+	if err := decoder.Decode(&tree); err != nil || decoder.More() {
+		return r.Truncate(r.mask(string(data)))
+	}
+
+	redacted, err := json.Marshal(r.redactTree(tree, tagged))
+	if err != nil {
+		return r.Truncate(r.mask(string(data)))
+	}
+
+	return r.Truncate(string(redacted))
+}
+
+func (r *Redactor) redactTree(node any, tagged *taggedFields) any {
+	switch value := node.(type) {
+	case map[string]any:
+		for key, child := range value {
+			childTagged := tagged.field(key)
+			if r.redactField(key) || (childTagged != nil && childTagged.redact) {
+				value[key] = RedactedValue
+				continue
+			}
+			value[key] = r.redactTree(child, childTagged)
+		}
+		return value
+	case []any:
+		for i, child := range value {
+			value[i] = r.redactTree(child, tagged)
+		}
+		return value
+	case string:
+		return r.mask(value)
+	case json.Number:
+		// card numbers sent as JSON numbers, masked number is written as string
+		if masked := r.mask(value.String()); masked != value.String() {
+			return masked
+		}
+		return value
+	default:
+		return value
+	}
+}
+
+func (r *Redactor) redactField(name string) bool {
+	_, ok := r.fields[normalizeField(name)]
+	return ok
+}
+
+func (r *Redactor) mask(s string) string {
+	for _, pattern := range r.patterns {
+		if pattern.match == nil {
+			s = pattern.re.ReplaceAllString(s, pattern.replacement)
+			continue
+		}
+
+		s = pattern.re.ReplaceAllStringFunc(s, func(match string) string {
+			if pattern.match(match) {
+				return pattern.replacement
+			}
+			return match
+		})
+	}
+	return s
+}
+
+// valueFields returns fields marked for redaction in the value type
+func (r *Redactor) valueFields(v any) *taggedFields {
+	if message, ok := v.(proto.Message); ok {
+		descriptor := message.ProtoReflect().Descriptor()
+		if fields, ok := r.typeFields.Load(descriptor.FullName()); ok {
+			return fields.(*taggedFields)
+		}
+
+		fields := protoRedactFields(descriptor, make(map[protoreflect.FullName]*taggedFields))
+		r.typeFields.Store(descriptor.FullName(), fields)
+		return fields
+	}
+
+	t := reflect.TypeOf(v)
+	if fields, ok := r.typeFields.Load(t); ok {
+		return fields.(*taggedFields)
+	}
+
+	fields := taggedRedactFields(t, make(map[reflect.Type]*taggedFields))
+	r.typeFields.Store(t, fields)
+	return fields
+}
+
+// taggedRedactFields builds fields of struct type, built keeps types already seen so
+// recursive types point to the same node.
+func taggedRedactFields(t reflect.Type, built map[reflect.Type]*taggedFields) *taggedFields {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+
+	if t.Kind() == reflect.Map {
+		values := taggedRedactFields(t.Elem(), built)
+		if values == nil {
+			return nil
+		}
+		return &taggedFields{values: values}
+	}
+
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	if fields, ok := built[t]; ok {
+		return fields
+	}
+
+	fields := &taggedFields{fields: make(map[string]*taggedFields)}
+	built[t] = fields
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		if field.Tag.Get(RedactTag) == "redact" {
+			if name == "" {
+				name = field.Name
+			}
+			fields.fields[normalizeField(name)] = &taggedFields{redact: true}
+			continue
+		}
+
+		child := taggedRedactFields(field.Type, built)
+		if child == nil {
+			continue
+		}
+
+		// fields of embedded struct are written into the parent object
+		if field.Anonymous && name == "" {
+			for childName, childField := range child.fields {
+				if _, ok := fields.fields[childName]; !ok {
+					fields.fields[childName] = childField
+				}
+			}
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		fields.fields[normalizeField(name)] = child
+	}
+
+	return fields
+}
+
+func protoRedactFields(
+	descriptor protoreflect.MessageDescriptor,
+	built map[protoreflect.FullName]*taggedFields,
+) *taggedFields {
+	if fields, ok := built[descriptor.FullName()]; ok {
+		return fields
+	}
+
+	fields := &taggedFields{fields: make(map[string]*taggedFields)}
+	built[descriptor.FullName()] = fields
+
+	for i := range descriptor.Fields().Len() {
+		field := descriptor.Fields().Get(i)
+
+		var child *taggedFields
+		switch {
+		case isDebugRedact(field):
+			child = &taggedFields{redact: true}
+		case field.IsMap() && field.MapValue().Message() != nil:
+			child = &taggedFields{values: protoRedactFields(field.MapValue().Message(), built)}
+		case field.Message() != nil && !field.IsMap():
+			child = protoRedactFields(field.Message(), built)
+		default:
+			continue
+		}
+
+		fields.fields[normalizeField(string(field.Name()))] = child
+		fields.fields[normalizeField(field.JSONName())] = child
+	}
+
+	return fields
+}
+
+func isDebugRedact(field protoreflect.FieldDescriptor) bool {
+	options, ok := field.Options().(interface{ GetDebugRedact() bool })
+	return ok && options.GetDebugRedact()
+}
+
+// ValidCardNumber reports whether digits of s pass the Luhn checksum, spaces and dashes
+// are ignored.
+func ValidCardNumber(s string) bool {
+	var sum, digits int
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c == ' ' || c == '-' {
+			continue
+		}
+		if c < '0' || c > '9' {
+			return false
+		}
+
+		digit := int(c - '0')
+		if digits%2 == 1 {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		digits++
+	}
+
+	return digits > 0 && sum%10 == 0
+}
+
+func normalizeField(name string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(name))
+}
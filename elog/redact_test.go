@@ -0,0 +1,112 @@
+package elog
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactor_JSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected string
+	}{
+		{
+			name:     "empty",
+			data:     "",
+			expected: "",
+		},
+		{
+			name:     "field names",
+			data:     `{"email":"user@test.com","password":"qwerty","Access-Token":"abc","nested":{"cardNumber":"1"}}`,
+			expected: `{"Access-Token":"[REDACTED]","email":"user@test.com","nested":{"cardNumber":"[REDACTED]"},"password":"[REDACTED]"}`,
+		},
+		{
+			name:     "array",
+			data:     `[{"secret":"s","amount":10.50}]`,
+			expected: `[{"amount":10.50,"secret":"[REDACTED]"}]`,
+		},
+		{
+			name:     "card number in string",
+			data:     `{"comment":"paid by 4111 1111 1111 1111"}`,
+			expected: `{"comment":"paid by [REDACTED]"}`,
+		},
+		{
+			name:     "digits failing luhn check",
+			data:     `{"created_at":"1760870400123","phone":"380501234567890"}`,
+			expected: `{"created_at":"1760870400123","phone":"380501234567890"}`,
+		},
+		{
+			name:     "card number in number",
+			data:     `{"pan":4111111111111111,"order_id":1760870400123,"amount":10.50}`,
+			expected: `{"amount":10.50,"order_id":1760870400123,"pan":"[REDACTED]"}`,
+		},
+		{
+			name:     "not json",
+			data:     `card=4111-1111-1111-1111&name=test`,
+			expected: `card=[REDACTED]&name=test`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, DefaultRedactor.JSON([]byte(test.data)))
+		})
+	}
+}
+
+func TestRedactor_Value(t *testing.T) {
+	type card struct {
+		Holder string `json:"holder"`
+		CVC    string `json:"cvc_code" log:"redact"`
+	}
+
+	type merchant struct {
+		Phone   string `json:"phone"`
+		CVCCode string `json:"cvc_code"`
+	}
+
+	type request struct {
+		Login    string          `json:"login"`
+		Phone    string          `json:"phone" log:"redact"`
+		Card     *card           `json:"card"`
+		Cards    map[string]card `json:"cards"`
+		Merchant merchant        `json:"merchant"`
+	}
+
+	redactor := NewRedactor(WithRedactFields("password"))
+
+	value := request{
+		Login:    "user",
+		Phone:    "+100",
+		Card:     &card{Holder: "John", CVC: "123"},
+		Cards:    map[string]card{"main": {Holder: "Jane", CVC: "456"}},
+		Merchant: merchant{Phone: "+200", CVCCode: "shop"},
+	}
+	assert.Equal(t,
+		`{"card":{"cvc_code":"[REDACTED]","holder":"John"},"cards":{"main":{"cvc_code":"[REDACTED]","holder":"Jane"}},`+
+			`"login":"user","merchant":{"cvc_code":"shop","phone":"+200"},"phone":"[REDACTED]"}`,
+		redactor.Value(value),
+	)
+	assert.Equal(t, `{"login":"user","password":"[REDACTED]"}`, redactor.Value(`{"login":"user","password":"1"}`))
+	assert.Equal(t, "", redactor.Value(nil))
+}
+
+func TestValidCardNumber(t *testing.T) {
+	assert.True(t, ValidCardNumber("4111 1111 1111 1111"))
+	assert.True(t, ValidCardNumber("5500-0000-0000-0004"))
+	assert.False(t, ValidCardNumber("4111 1111 1111 1112"))
+	assert.False(t, ValidCardNumber("1760870400123"))
+	assert.False(t, ValidCardNumber(""))
+}
+
+func TestRedactor_Truncate(t *testing.T) {
+	redactor := NewRedactor(WithMaxSize(5))
+
+	assert.Equal(t, "short", redactor.Truncate("short"))
+	assert.Equal(t, "long ...[truncated 5 bytes]", redactor.Truncate("long value"))
+	assert.Equal(t, "abп...[truncated 4 bytes]", redactor.Truncate("abпри"))
+	assert.Equal(t, strings.Repeat("a", 10), NewRedactor().Truncate(strings.Repeat("a", 10)))
+}
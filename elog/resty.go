@@ -10,6 +10,8 @@ import (
 
 type RestyLogger struct {
 	ServiceName string
+	// Redactor masks logged bodies, DefaultRedactor is used when nil.
+	Redactor *Redactor
 }
 
 func (r *RestyLogger) Info(resp *resty.Response, msg string) {
@@ -40,12 +42,17 @@ func (r *RestyLogger) log(resp *resty.Response, err error, msg string) {
 		slog.Int(string(semconv.HTTPResponseStatusCodeKey), resp.StatusCode()),
 	)
 
+	redactor := r.Redactor
+	if redactor == nil {
+		redactor = DefaultRedactor
+	}
+
 	if resp.String() != "" {
-		logger = logger.With(slog.String(HTTPResponseBodyContent, resp.String()))
+		logger = logger.With(slog.String(HTTPResponseBodyContent, redactor.JSON(resp.Bytes())))
 	}
 
 	if resp.Request.Body != nil {
-		logger = logger.With(slog.Any(HTTPRequestBodyContent, resp.Request.Body))
+		logger = logger.With(slog.String(HTTPRequestBodyContent, redactor.Value(resp.Request.Body)))
 	}
 
 	if resp.Request.IsTrace {
@@ -0,0 +1,132 @@
+package grpc
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/resolver"
+)
+
+// Load balancing policies for ClientConnectionParams.LoadBalancing.
+const (
+	BalancerPickFirst            = "pick_first"
+	BalancerRoundRobin           = "round_robin"
+	BalancerWeightedLeastRequest = "weighted_least_request"
+)
+
+func init() {
+	balancer.Register(leastRequestBuilder{})
+}
+
+// ServiceConfig returns default service config JSON selecting the load balancing policy.
+func ServiceConfig(loadBalancing string) string {
+	return fmt.Sprintf(`{"loadBalancingConfig":[{%q:{}}]}`, loadBalancing)
+}
+
+type weightKey struct{}
+
+// SetAddressWeight sets balancer weight of address, zero weight is treated as 1.
+func SetAddressWeight(address resolver.Address, weight uint32) resolver.Address {
+	if weight == 0 {
+		return address
+	}
+	address.BalancerAttributes = address.BalancerAttributes.WithValue(weightKey{}, weight)
+	return address
+}
+
+// AddressWeight returns balancer weight of address, 1 when it is not set.
+func AddressWeight(address resolver.Address) uint32 {
+	if weight, ok := address.BalancerAttributes.Value(weightKey{}).(uint32); ok && weight > 0 {
+		return weight
+	}
+	return 1
+}
+
+// leastRequestBuilder creates balancer with own picker builder per connection, so in-flight
+// counters are not shared between connections.
+type leastRequestBuilder struct{}
+
+func (leastRequestBuilder) Build(cc balancer.ClientConn, opts balancer.BuildOptions) balancer.Balancer {
+	builder := base.NewBalancerBuilder(BalancerWeightedLeastRequest, &leastRequestPickerBuilder{}, base.Config{
+		HealthCheck: true,
+	})
+	return builder.Build(cc, opts)
+}
+
+func (leastRequestBuilder) Name() string {
+	return BalancerWeightedLeastRequest
+}
+
+// leastRequestPickerBuilder keeps in-flight counters between pickers, the picker is
+// rebuilt on every sub connection state change.
+type leastRequestPickerBuilder struct {
+	mu       sync.Mutex
+	inFlight map[balancer.SubConn]*atomic.Int64
+}
+
+func (b *leastRequestPickerBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
+	if len(info.ReadySCs) == 0 {
+		return base.NewErrPicker(balancer.ErrNoSubConnAvailable)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	inFlight := make(map[balancer.SubConn]*atomic.Int64, len(info.ReadySCs))
+	picker := &leastRequestPicker{}
+	for subConn, subConnInfo := range info.ReadySCs {
+		counter, ok := b.inFlight[subConn]
+		if !ok {
+			counter = &atomic.Int64{}
+		}
+		inFlight[subConn] = counter
+
+		picker.subConns = append(picker.subConns, leastRequestSubConn{
+			subConn:  subConn,
+			weight:   int64(AddressWeight(subConnInfo.Address)),
+			inFlight: counter,
+		})
+	}
+	b.inFlight = inFlight
+
+	return picker
+}
+
+type leastRequestSubConn struct {
+	subConn  balancer.SubConn
+	weight   int64
+	inFlight *atomic.Int64
+}
+
+// leastRequestPicker picks two random sub connections and uses the one with less in-flight
+// requests per weight, the power of two choices avoids herding on a single replica.
+type leastRequestPicker struct {
+	subConns []leastRequestSubConn
+}
+
+func (p *leastRequestPicker) Pick(balancer.PickInfo) (balancer.PickResult, error) {
+	picked := p.subConns[0]
+	if len(p.subConns) > 1 {
+		first := rand.IntN(len(p.subConns))
+		second := rand.IntN(len(p.subConns) - 1)
+		if second >= first {
+			second++
+		}
+
+		picked = p.subConns[first]
+		if other := p.subConns[second]; other.inFlight.Load()*picked.weight < picked.inFlight.Load()*other.weight {
+			picked = other
+		}
+	}
+
+	picked.inFlight.Add(1)
+
+	return balancer.PickResult{
+		SubConn: picked.subConn,
+		Done:    func(balancer.DoneInfo) { picked.inFlight.Add(-1) },
+	}, nil
+}
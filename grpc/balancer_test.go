@@ -0,0 +1,259 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stepanbukhtii/easy-tools/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const testBlockKey = "x-test-block"
+
+type testBackend struct {
+	listener *bufconn.Listener
+	calls    atomic.Int64
+	blocked  atomic.Int64
+	release  chan struct{}
+}
+
+// startBackends starts in-process servers named backend-N, dial option routes the names
+// to their listeners.
+func startBackends(t *testing.T, count int) ([]*testBackend, []string, grpc.DialOption) {
+	backends := make(map[string]*testBackend, count)
+	list := make([]*testBackend, count)
+	names := make([]string, count)
+
+	for i := range count {
+		backend := &testBackend{
+			listener: bufconn.Listen(1 << 20),
+			release:  make(chan struct{}),
+		}
+
+		server := NewServerParams(ServerParams{
+			Config: config.GRPC{HealthEnabled: true},
+			UnaryInterceptors: []grpc.UnaryServerInterceptor{
+				func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+					backend.calls.Add(1)
+					if len(metadata.ValueFromIncomingContext(ctx, testBlockKey)) > 0 {
+						backend.blocked.Add(1)
+						<-backend.release
+					}
+					return handler(ctx, req)
+				},
+			},
+		})
+		go func() { _ = server.Server.Serve(backend.listener) }()
+		t.Cleanup(server.Stop)
+
+		names[i] = "backend-" + string(rune('a'+i))
+		backends[names[i]] = backend
+		list[i] = backend
+	}
+
+	dialer := grpc.WithContextDialer(func(ctx context.Context, address string) (net.Conn, error) {
+		return backends[address].listener.DialContext(ctx)
+	})
+
+	return list, names, dialer
+}
+
+// warmUp calls until every backend has a ready sub connection and served a call
+func warmUp(t *testing.T, client grpc_health_v1.HealthClient, backends []*testBackend) {
+	require.Eventually(t, func() bool {
+		if _, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{}); err != nil {
+			return false
+		}
+
+		for _, backend := range backends {
+			if backend.calls.Load() == 0 {
+				return false
+			}
+		}
+		return true
+	}, 5*time.Second, time.Millisecond)
+
+	for _, backend := range backends {
+		backend.calls.Store(0)
+	}
+}
+
+func TestLoadBalancing_RoundRobin(t *testing.T) {
+	backends, names, dialer := startBackends(t, 3)
+
+	conn, err := NewClientConnectionParams("static:///orders", ClientConnectionParams{
+		Resolver:      NewStaticResolver(names...),
+		LoadBalancing: BalancerRoundRobin,
+		DialOptions:   []grpc.DialOption{dialer},
+	})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	client := grpc_health_v1.NewHealthClient(conn)
+	warmUp(t, client, backends)
+
+	for range 30 {
+		_, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+		require.NoError(t, err)
+	}
+
+	for _, backend := range backends {
+		require.Equal(t, int64(10), backend.calls.Load())
+	}
+}
+
+func TestLoadBalancing_WeightedLeastRequest(t *testing.T) {
+	backends, names, dialer := startBackends(t, 3)
+
+	conn, err := NewClientConnectionParams("static:///orders", ClientConnectionParams{
+		Resolver:      NewStaticResolver(names...),
+		LoadBalancing: BalancerWeightedLeastRequest,
+		DialOptions:   []grpc.DialOption{dialer},
+	})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	client := grpc_health_v1.NewHealthClient(conn)
+	warmUp(t, client, backends)
+
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		ctx := metadata.AppendToOutgoingContext(context.Background(), testBlockKey, "true")
+		_, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+		assert.NoError(t, err)
+	})
+
+	var busy *testBackend
+	require.Eventually(t, func() bool {
+		for _, backend := range backends {
+			if backend.blocked.Load() > 0 {
+				busy = backend
+				return true
+			}
+		}
+		return false
+	}, 5*time.Second, time.Millisecond)
+
+	for range 30 {
+		_, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+		require.NoError(t, err)
+	}
+
+	// the busy backend loses every choice against idle ones
+	for _, backend := range backends {
+		if backend == busy {
+			require.Equal(t, int64(1), backend.calls.Load())
+		} else {
+			require.Positive(t, backend.calls.Load())
+		}
+	}
+
+	close(busy.release)
+	wg.Wait()
+}
+
+func TestLeastRequestPicker_Weight(t *testing.T) {
+	heavy := leastRequestSubConn{weight: 3, inFlight: &atomic.Int64{}}
+	heavy.inFlight.Store(2)
+	light := leastRequestSubConn{weight: 1, inFlight: &atomic.Int64{}}
+	light.inFlight.Store(1)
+
+	picker := &leastRequestPicker{subConns: []leastRequestSubConn{heavy, light}}
+
+	for range 10 {
+		result, err := picker.Pick(balancer.PickInfo{})
+		require.NoError(t, err)
+		result.Done(balancer.DoneInfo{})
+	}
+
+	require.Equal(t, int64(2), heavy.inFlight.Load())
+	require.Equal(t, int64(1), light.inFlight.Load())
+}
+
+func TestRegistryResolver(t *testing.T) {
+	backends, names, dialer := startBackends(t, 2)
+
+	var instances atomic.Value
+	instances.Store([]Instance{{Address: names[0]}})
+	registry := RegistryFunc(func(_ context.Context, service string) ([]Instance, error) {
+		assert.Equal(t, "orders", service)
+		return instances.Load().([]Instance), nil
+	})
+
+	conn, err := NewClientConnectionParams("registry:///orders", ClientConnectionParams{
+		Resolver:      NewResolver("registry", registry, 10*time.Millisecond),
+		LoadBalancing: BalancerRoundRobin,
+		DialOptions:   []grpc.DialOption{dialer},
+	})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	client := grpc_health_v1.NewHealthClient(conn)
+	_, err = client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), backends[0].calls.Load())
+
+	instances.Store([]Instance{{Address: names[1]}})
+
+	require.Eventually(t, func() bool {
+		_, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+		return err == nil && backends[1].calls.Load() > 0
+	}, 5*time.Second, 5*time.Millisecond)
+}
+
+func TestDNSRegistry(t *testing.T) {
+	instances, err := DNSRegistry(nil).Lookup(context.Background(), "localhost:50051")
+	require.NoError(t, err)
+	require.NotEmpty(t, instances)
+	require.Contains(t, instances, Instance{Address: "127.0.0.1:50051"})
+
+	instances, err = DNSRegistry(nil).Lookup(context.Background(), "localhost")
+	require.NoError(t, err)
+	require.Contains(t, instances, Instance{Address: "127.0.0.1:443"})
+}
+
+type testResolverConn struct {
+	resolver.ClientConn
+}
+
+func (c *testResolverConn) UpdateState(resolver.State) error { return nil }
+
+func (c *testResolverConn) ReportError(error) {}
+
+func TestRegistryResolver_ResolveNow(t *testing.T) {
+	var lookups atomic.Int64
+	registry := RegistryFunc(func(context.Context, string) ([]Instance, error) {
+		lookups.Add(1)
+		return []Instance{{Address: "backend-a"}}, nil
+	})
+
+	builder := NewResolver("registry", registry, 0, WithMinResolveInterval(100*time.Millisecond))
+	r, err := builder.Build(resolver.Target{URL: url.URL{Scheme: "registry", Path: "/orders"}},
+		&testResolverConn{}, resolver.BuildOptions{})
+	require.NoError(t, err)
+	defer r.Close()
+
+	require.Eventually(t, func() bool { return lookups.Load() == 1 }, time.Second, time.Millisecond)
+
+	for range 10 {
+		r.ResolveNow(resolver.ResolveNowOptions{})
+	}
+	time.Sleep(50 * time.Millisecond)
+	require.Equal(t, int64(1), lookups.Load())
+
+	require.Eventually(t, func() bool { return lookups.Load() == 2 }, time.Second, time.Millisecond)
+	time.Sleep(150 * time.Millisecond)
+	require.Equal(t, int64(2), lookups.Load())
+}
@@ -1,64 +1,210 @@
 package grpc
 
 import (
+	"context"
+	"log/slog"
+	"slices"
 	"time"
 
-	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/retry"
+	"github.com/stepanbukhtii/easy-tools/config"
 	"github.com/stepanbukhtii/easy-tools/grpc/interceptor"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	semconv "go.opentelemetry.io/otel/semconv/v1.40.0"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/resolver"
 )
 
 var (
 	PerRetryTimeout = 30 * time.Second
 	MaxRetry        = uint(3)
 
+	// DefaultCallTimeout applies to calls without deadline on the context.
+	DefaultCallTimeout = time.Minute
+
+	// DefaultClientInterceptors run once per call, retries are chained after them so every
+	// attempt carries the same auth metadata and is logged as one call.
 	DefaultClientInterceptors = []grpc.UnaryClientInterceptor{
+		interceptor.ClientErrors,
 		interceptor.ClientLogger,
-		retry.UnaryClientInterceptor(
-			retry.WithMax(MaxRetry),
-			retry.WithPerRetryTimeout(PerRetryTimeout),
-			retry.WithBackoff(retry.BackoffExponential(100*time.Millisecond)),
-		),
 		interceptor.ClientAuth,
 	}
+	DefaultClientStreamInterceptors = []grpc.StreamClientInterceptor{
+		interceptor.StreamClientErrors,
+		interceptor.StreamClientLogger,
+		interceptor.StreamClientAuth,
+	}
 	DefaultClientDialOptions = []grpc.DialOption{
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
 		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
-		grpc.WithChainUnaryInterceptor(DefaultClientInterceptors...),
+		grpc.WithChainUnaryInterceptor(append(slices.Clone(DefaultClientInterceptors),
+			DefaultPropagation.UnaryClient,
+			interceptor.NewTimeout(DefaultCallTimeout, nil).Unary,
+			defaultRetry().Unary,
+		)...),
+		grpc.WithChainStreamInterceptor(append(slices.Clone(DefaultClientStreamInterceptors),
+			DefaultPropagation.StreamClient,
+		)...),
 	}
 )
 
 func NewClientConnection(address string) (*grpc.ClientConn, error) {
-	return grpc.NewClient(address, DefaultClientDialOptions...)
+	conn, err := grpc.NewClient(address, DefaultClientDialOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	go watchConnection(conn)
+
+	return conn, nil
+}
+
+// NewClientConnectionConfig creates connection with TLS or mTLS credentials from config.
+func NewClientConnectionConfig(address string, cfg config.GRPC) (*grpc.ClientConn, error) {
+	creds, err := ClientCredentials(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewClientConnectionParams(address, ClientConnectionParams{TransportCredentials: creds})
 }
 
 type ClientConnectionParams struct {
 	TransportCredentials credentials.TransportCredentials
 	UnaryInterceptors    []grpc.UnaryClientInterceptor
+	StreamInterceptors   []grpc.StreamClientInterceptor
 	DialOptions          []grpc.DialOption
+	// Propagation replaces DefaultPropagation
+	Propagation *interceptor.Propagation
+
+	// DefaultTimeout replaces DefaultCallTimeout for calls without deadline
+	DefaultTimeout *time.Duration
+	MethodTimeouts interceptor.MethodTimeouts
+
+	// RetryPolicy replaces the default policy built from MaxRetry and PerRetryTimeout
+	RetryPolicy         *interceptor.RetryPolicy
+	MethodRetryPolicies interceptor.MethodRetryPolicies
+	RetryBudget         *interceptor.RetryBudget
+	// CircuitBreaker enables circuit breaker named after the address
+	CircuitBreaker *interceptor.CircuitBreakerConfig
+
+	// Resolver resolves the address scheme, e.g. NewDNSResolver for "dns:///orders:50051"
+	Resolver resolver.Builder
+	// LoadBalancing is a balancer policy, e.g. BalancerRoundRobin, pick first is used when empty
+	LoadBalancing string
 }
 
+// NewClientConnectionParams creates connection, interceptors are chained as: default
+// interceptors, metadata propagation, params interceptors, timeout, circuit breaker, retry.
 func NewClientConnectionParams(address string, params ClientConnectionParams) (*grpc.ClientConn, error) {
 	if params.TransportCredentials == nil {
 		params.TransportCredentials = insecure.NewCredentials()
 	}
 
-	unaryInterceptors := DefaultClientInterceptors
-	if len(params.UnaryInterceptors) > 0 {
-		unaryInterceptors = append(unaryInterceptors, params.UnaryInterceptors...)
+	propagation := params.Propagation
+	if propagation == nil {
+		propagation = DefaultPropagation
+	}
+
+	unaryInterceptors := slices.Concat(
+		DefaultClientInterceptors,
+		[]grpc.UnaryClientInterceptor{propagation.UnaryClient},
+		params.UnaryInterceptors,
+	)
+	streamInterceptors := slices.Concat(
+		DefaultClientStreamInterceptors,
+		[]grpc.StreamClientInterceptor{propagation.StreamClient},
+		params.StreamInterceptors,
+	)
+
+	defaultTimeout := DefaultCallTimeout
+	if params.DefaultTimeout != nil {
+		defaultTimeout = *params.DefaultTimeout
+	}
+	unaryInterceptors = append(unaryInterceptors, interceptor.NewTimeout(defaultTimeout, params.MethodTimeouts).Unary)
+
+	var onShutdown []func()
+	if params.CircuitBreaker != nil {
+		circuitBreaker, err := interceptor.NewCircuitBreaker(address, *params.CircuitBreaker)
+		if err != nil {
+			return nil, err
+		}
+		onShutdown = append(onShutdown, circuitBreaker.Close)
+		unaryInterceptors = append(unaryInterceptors, circuitBreaker.Unary)
+		streamInterceptors = append(streamInterceptors, circuitBreaker.Stream)
 	}
 
+	retryPolicy := defaultRetryPolicy()
+	if params.RetryPolicy != nil {
+		retryPolicy = *params.RetryPolicy
+	}
+	retry := interceptor.NewRetry(retryPolicy, params.MethodRetryPolicies, params.RetryBudget)
+	unaryInterceptors = append(unaryInterceptors, retry.Unary)
+
 	dialOpts := []grpc.DialOption{
 		grpc.WithTransportCredentials(params.TransportCredentials),
 		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
 		grpc.WithChainUnaryInterceptor(unaryInterceptors...),
+		grpc.WithChainStreamInterceptor(streamInterceptors...),
+	}
+	if params.Resolver != nil {
+		dialOpts = append(dialOpts, grpc.WithResolvers(params.Resolver))
+	}
+	if params.LoadBalancing != "" {
+		dialOpts = append(dialOpts, grpc.WithDefaultServiceConfig(ServiceConfig(params.LoadBalancing)))
 	}
 	if params.DialOptions != nil {
 		dialOpts = append(dialOpts, params.DialOptions...)
 	}
 
-	return grpc.NewClient(address, dialOpts...)
+	conn, err := grpc.NewClient(address, dialOpts...)
+	if err != nil {
+		for _, shutdown := range onShutdown {
+			shutdown()
+		}
+		return nil, err
+	}
+
+	go watchConnection(conn, onShutdown...)
+
+	return conn, nil
+}
+
+// watchConnection logs connectivity changes until the connection is closed, then runs
+// onShutdown. Transient failures are logged at warn level, other states at debug level.
+func watchConnection(conn *grpc.ClientConn, onShutdown ...func()) {
+	defer func() {
+		for _, shutdown := range onShutdown {
+			shutdown()
+		}
+	}()
+
+	logger := slog.With(slog.String(string(semconv.ServerAddressKey), conn.Target()))
+
+	state := conn.GetState()
+	for state != connectivity.Shutdown {
+		if !conn.WaitForStateChange(context.Background(), state) {
+			return
+		}
+		state = conn.GetState()
+
+		level := slog.LevelDebug
+		if state == connectivity.TransientFailure {
+			level = slog.LevelWarn
+		}
+		logger.Log(context.Background(), level, "gRPC connection state changed", slog.String("state", state.String()))
+	}
+}
+
+func defaultRetryPolicy() interceptor.RetryPolicy {
+	policy := interceptor.DefaultRetryPolicy
+	policy.MaxRetries = int(MaxRetry)
+	policy.PerAttemptTimeout = PerRetryTimeout
+	return policy
+}
+
+func defaultRetry() *interceptor.Retry {
+	return interceptor.NewRetry(defaultRetryPolicy(), nil, nil)
 }
@@ -0,0 +1,73 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/stepanbukhtii/easy-tools/apikey"
+)
+
+type APIKeyAuth struct {
+	authenticator *apikey.Authenticator
+	header        string
+	skipMethods   []string
+}
+
+// NewAPIKeyAuth reads key from metadata header, empty header uses apikey.DefaultHeader.
+// Methods of DefaultAuthSkipMethods are not authenticated.
+func NewAPIKeyAuth(authenticator *apikey.Authenticator, header string) *APIKeyAuth {
+	if header == "" {
+		header = apikey.DefaultHeader
+	}
+
+	return &APIKeyAuth{
+		authenticator: authenticator,
+		header:        strings.ToLower(header),
+		skipMethods:   DefaultAuthSkipMethods,
+	}
+}
+
+func (a APIKeyAuth) Unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := a.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+func (a APIKeyAuth) Stream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := a.authenticate(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+
+	return handler(srv, wrapServerStream(ss, ctx))
+}
+
+func (a APIKeyAuth) authenticate(ctx context.Context, method string) (context.Context, error) {
+	if skipMethod(a.skipMethods, method) {
+		return ctx, nil
+	}
+
+	var plainKey string
+	if vals := metadata.ValueFromIncomingContext(ctx, a.header); len(vals) > 0 {
+		plainKey = vals[0]
+	}
+
+	key, err := a.authenticator.Authenticate(ctx, plainKey)
+	switch {
+	case errors.Is(err, apikey.ErrKeyMissing), errors.Is(err, apikey.ErrKeyInvalid), errors.Is(err, apikey.ErrKeyExpired):
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	case err != nil:
+		return nil, status.Error(codes.Internal, "api key lookup failed")
+	}
+
+	return key.SetContext(ctx), nil
+}
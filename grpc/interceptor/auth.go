@@ -3,12 +3,14 @@ package interceptor
 import (
 	"context"
 	"crypto/ed25519"
+	"errors"
 	"fmt"
+	"log/slog"
 	"strings"
 
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/stepanbukhtii/easy-tools/econtext"
 	"github.com/stepanbukhtii/easy-tools/ejwt"
+	"github.com/stepanbukhtii/easy-tools/elog"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
@@ -20,123 +22,192 @@ var (
 	HeaderAuthorization = "authorization"
 )
 
-type JWTAuth struct {
-	publicKey            ed25519.PublicKey
-	parser               *jwt.Parser
-	skipValidation       bool
-	expirationValidation bool
+// DefaultAuthSkipMethods are not authenticated by JWTAuth, an entry ending with "/"
+// skips every method of the service.
+var DefaultAuthSkipMethods = []string{
+	"/grpc.health.v1.Health/",
+	"/grpc.reflection.v1.ServerReflection/",
+	"/grpc.reflection.v1alpha.ServerReflection/",
 }
 
-func NewJWTAuth(publicKey ed25519.PublicKey, issuer, audience string, skipValidation, expirationValidate bool) *JWTAuth {
-	options := []jwt.ParserOption{
-		jwt.WithValidMethods([]string{jwt.SigningMethodEdDSA.Alg()}),
-		jwt.WithIssuer(issuer),
-		jwt.WithAudience(audience),
-		jwt.WithIssuedAt(),
-	}
+// JWTAuthOption configures JWTAuth.
+type JWTAuthOption func(*JWTAuth)
 
-	if expirationValidate {
-		options = append(options, jwt.WithExpirationRequired())
+// WithOptionalAuth lets requests without a token pass through anonymously, a
+// present token still has to be valid.
+func WithOptionalAuth() JWTAuthOption {
+	return func(m *JWTAuth) {
+		m.optional = true
 	}
+}
 
-	return &JWTAuth{
-		publicKey:      publicKey,
-		parser:         jwt.NewParser(options...),
-		skipValidation: skipValidation,
+// WithSkipMethods replaces DefaultAuthSkipMethods.
+func WithSkipMethods(methods ...string) JWTAuthOption {
+	return func(m *JWTAuth) {
+		m.skipMethods = methods
 	}
 }
 
-// Auth parse and verifying JWT token
-func (m JWTAuth) Auth(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
-	tokenString, err := extractAuthToken(ctx)
-	if err != nil {
-		return nil, err
-	}
-	if tokenString == "" {
-		return ctx, nil
-	}
+type JWTAuth struct {
+	verifier       *ejwt.Verifier
+	skipValidation bool
+	optional       bool
+	skipMethods    []string
+}
 
-	var claims jwt.RegisteredClaims
+func NewJWTAuth(
+	publicKey ed25519.PublicKey,
+	issuer, audience string,
+	skipValidation, expirationValidate bool,
+	opts ...JWTAuthOption,
+) *JWTAuth {
+	var options []ejwt.VerifierOption
+	if !expirationValidate {
+		options = append(options, ejwt.WithExpirationOptional())
+	}
 
-	if m.skipValidation {
-		if _, _, err := m.parser.ParseUnverified(tokenString, &claims); err != nil {
-			return nil, status.Error(codes.Unauthenticated, "parse token failed")
-		}
+	return NewJWTAuthVerifier(ejwt.NewVerifier(publicKey, issuer, audience, options...), skipValidation, opts...)
+}
 
-		if claims.Subject != "" {
-			ctx = econtext.SetSubject(ctx, claims.Subject)
-		}
+func NewJWTAuthVerifier(verifier *ejwt.Verifier, skipValidation bool, opts ...JWTAuthOption) *JWTAuth {
+	m := &JWTAuth{
+		verifier:       verifier,
+		skipValidation: skipValidation,
+		skipMethods:    DefaultAuthSkipMethods,
+	}
 
-		return handler(ctx, req)
+	for _, opt := range opts {
+		opt(m)
 	}
 
-	keyFunc := func(token *jwt.Token) (interface{}, error) { return m.publicKey, nil }
-	token, err := m.parser.ParseWithClaims(tokenString, &claims, keyFunc)
+	return m
+}
+
+// Auth parse and verifying JWT token
+func (m JWTAuth) Auth(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := m.authenticate(ctx, info.FullMethod, "")
 	if err != nil {
-		return nil, status.Error(codes.Unauthenticated, "parse token failed")
+		return nil, err
 	}
 
-	if !token.Valid || claims.Subject == "" {
-		return nil, status.Error(codes.Unauthenticated, "token validating failed")
-	}
+	return handler(ctx, req)
+}
 
-	ctx = econtext.SetSubject(ctx, claims.Subject)
+// Stream parse and verifying JWT token of the stream
+func (m JWTAuth) Stream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := m.authenticate(ss.Context(), info.FullMethod, "")
+	if err != nil {
+		return err
+	}
 
-	return handler(ctx, req)
+	return handler(srv, wrapServerStream(ss, ctx))
 }
 
 // AuthRole parse and verifying JWT token and check role
 func (m JWTAuth) AuthRole(role string) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
-		tokenString, err := extractAuthToken(ctx)
+		ctx, err := m.authenticate(ctx, info.FullMethod, role)
 		if err != nil {
 			return nil, err
 		}
-		if tokenString == "" {
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamRole parse and verifying JWT token of the stream and check role
+func (m JWTAuth) StreamRole(role string) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := m.authenticate(ss.Context(), info.FullMethod, role)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, wrapServerStream(ss, ctx))
+	}
+}
+
+// authenticate returns ctx with the token and its claims, empty role skips the role check
+func (m JWTAuth) authenticate(ctx context.Context, method, role string) (context.Context, error) {
+	if skipMethod(m.skipMethods, method) {
+		return ctx, nil
+	}
+
+	tokenString, err := extractAuthToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if tokenString == "" {
+		if m.optional && role == "" {
 			return ctx, nil
 		}
+		return nil, status.Error(codes.Unauthenticated, "no authorization token")
+	}
 
-		var claims ejwt.ClaimsWithRoles
+	claims, err := m.parseToken(ctx, tokenString)
+	if err != nil {
+		return nil, err
+	}
 
-		if m.skipValidation {
-			if _, _, err := m.parser.ParseUnverified(tokenString, &claims); err != nil {
-				return nil, status.Error(codes.Unauthenticated, "parse token failed")
-			}
+	if role != "" && !m.skipValidation && !claims.HasRole(role) {
+		return nil, status.Errorf(codes.PermissionDenied, "role %s required", role)
+	}
 
-			if claims.Subject != "" {
-				ctx = econtext.SetSubject(ctx, claims.Subject)
-			}
+	ctx = econtext.SetAuthToken(ctx, tokenString)
 
-			if claims.Roles != nil {
-				ctx = econtext.SetRoles(ctx, claims.Roles)
-			}
+	return setClaims(ctx, claims), nil
+}
 
-			return handler(ctx, req)
+func skipMethod(skipMethods []string, method string) bool {
+	for _, skip := range skipMethods {
+		if method == skip || (strings.HasSuffix(skip, "/") && strings.HasPrefix(method, skip)) {
+			return true
 		}
+	}
+	return false
+}
 
-		keyFunc := func(token *jwt.Token) (interface{}, error) { return m.publicKey, nil }
-		token, err := m.parser.ParseWithClaims(tokenString, &claims, keyFunc)
-		if err != nil {
-			return nil, status.Error(codes.Unauthenticated, "parse token failed")
-		}
+func (m JWTAuth) parseToken(ctx context.Context, tokenString string) (ejwt.Claims, error) {
+	var claims ejwt.Claims
 
-		var hasRole bool
-		for _, claimRole := range claims.Roles {
-			if claimRole == role {
-				hasRole = true
-				break
-			}
+	if m.skipValidation {
+		if err := m.verifier.ParseUnverified(tokenString, &claims); err != nil {
+			return claims, status.Error(codes.Unauthenticated, "parse token failed")
 		}
 
-		if !token.Valid || claims.Subject == "" || !hasRole {
-			return nil, status.Error(codes.Unauthenticated, "token validating failed")
-		}
+		return claims, nil
+	}
 
+	claims, err := m.verifier.VerifyClaimsContext(ctx, tokenString)
+	switch {
+	case errors.Is(err, ejwt.ErrTokenRevoked):
+		return claims, status.Error(codes.Unauthenticated, "token revoked")
+	case errors.Is(err, ejwt.ErrRevocationCheck):
+		slog.With(elog.Err(err)).ErrorContext(ctx, "jwt revocation check failed")
+		return claims, status.Error(codes.Internal, ejwt.ErrRevocationCheck.Error())
+	case errors.Is(err, ejwt.ErrInvalidToken), errors.Is(err, ejwt.ErrEmptySubject):
+		return claims, status.Error(codes.Unauthenticated, "token validating failed")
+	case err != nil:
+		return claims, status.Error(codes.Unauthenticated, "parse token failed")
+	}
+
+	return claims, nil
+}
+
+func setClaims(ctx context.Context, claims ejwt.Claims) context.Context {
+	if claims.Subject != "" {
 		ctx = econtext.SetSubject(ctx, claims.Subject)
+	}
+
+	if claims.Roles != nil {
 		ctx = econtext.SetRoles(ctx, claims.Roles)
+	}
 
-		return handler(ctx, req)
+	if scopes := claims.Scopes(); scopes != nil {
+		ctx = econtext.SetScopes(ctx, scopes)
 	}
+
+	return ctx
 }
 
 func ClientAuth(
@@ -147,9 +218,68 @@ func ClientAuth(
 	invoker grpc.UnaryInvoker,
 	opts ...grpc.CallOption,
 ) error {
-	token := econtext.AuthToken(ctx)
+	return invoker(setOutgoingAuthToken(ctx, econtext.AuthToken(ctx)), method, req, reply, cc, opts...)
+}
+
+// ClientServiceAuth forwards user token like ClientAuth, calls without user token are
+// authenticated with a service token for the audience service.
+func ClientServiceAuth(tokens ejwt.TokenSource, audience string) grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply any,
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		token := econtext.AuthToken(ctx)
+		if token == "" {
+			var err error
+			if token, err = tokens.Token(ctx, audience); err != nil {
+				return status.Errorf(codes.Unauthenticated, "service token: %v", err)
+			}
+		}
+
+		return invoker(setOutgoingAuthToken(ctx, token), method, req, reply, cc, opts...)
+	}
+}
+
+func StreamClientAuth(
+	ctx context.Context,
+	desc *grpc.StreamDesc,
+	cc *grpc.ClientConn,
+	method string,
+	streamer grpc.Streamer,
+	opts ...grpc.CallOption,
+) (grpc.ClientStream, error) {
+	return streamer(setOutgoingAuthToken(ctx, econtext.AuthToken(ctx)), desc, cc, method, opts...)
+}
+
+// StreamClientServiceAuth is the stream variant of ClientServiceAuth.
+func StreamClientServiceAuth(tokens ejwt.TokenSource, audience string) grpc.StreamClientInterceptor {
+	return func(
+		ctx context.Context,
+		desc *grpc.StreamDesc,
+		cc *grpc.ClientConn,
+		method string,
+		streamer grpc.Streamer,
+		opts ...grpc.CallOption,
+	) (grpc.ClientStream, error) {
+		token := econtext.AuthToken(ctx)
+		if token == "" {
+			var err error
+			if token, err = tokens.Token(ctx, audience); err != nil {
+				return nil, status.Errorf(codes.Unauthenticated, "service token: %v", err)
+			}
+		}
+
+		return streamer(setOutgoingAuthToken(ctx, token), desc, cc, method, opts...)
+	}
+}
+
+func setOutgoingAuthToken(ctx context.Context, token string) context.Context {
 	if token == "" {
-		return invoker(ctx, method, req, reply, cc, opts...)
+		return ctx
 	}
 
 	token = fmt.Sprintf("%s %s", AuthScheme, token)
@@ -162,9 +292,7 @@ func ClientAuth(
 		md.Set(HeaderAuthorization, token)
 	}
 
-	ctx = metadata.NewOutgoingContext(ctx, md)
-
-	return invoker(ctx, method, req, reply, cc, opts...)
+	return metadata.NewOutgoingContext(ctx, md)
 }
 
 func extractAuthToken(ctx context.Context) (string, error) {
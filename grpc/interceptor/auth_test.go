@@ -0,0 +1,201 @@
+package interceptor
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/stepanbukhtii/easy-tools/econtext"
+	"github.com/stepanbukhtii/easy-tools/ejwt"
+)
+
+const (
+	testIssuer   = "issuer"
+	testAudience = "audience"
+	testSubject  = "subject"
+	testRole     = "role"
+	testMethod   = "/test.v1.Service/Method"
+)
+
+type testServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s testServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestJWTAuth(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	jwtGenerator := ejwt.NewJWTGenerator(privateKey, testIssuer, testAudience, time.Minute)
+
+	token, err := jwtGenerator.GenerateToken(testSubject, ejwt.WithRoles(testRole))
+	require.NoError(t, err)
+
+	tokenWithoutRole, err := jwtGenerator.GenerateToken(testSubject)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name            string
+		opts            []JWTAuthOption
+		method          string
+		role            string
+		token           string
+		expectedCode    codes.Code
+		expectedSubject string
+	}{
+		{
+			name:            "success",
+			method:          testMethod,
+			token:           token,
+			expectedSubject: testSubject,
+		}, {
+			name:         "no token",
+			method:       testMethod,
+			expectedCode: codes.Unauthenticated,
+		}, {
+			name:         "invalid token",
+			method:       testMethod,
+			token:        "invalid",
+			expectedCode: codes.Unauthenticated,
+		}, {
+			name:   "optional without token",
+			opts:   []JWTAuthOption{WithOptionalAuth()},
+			method: testMethod,
+		}, {
+			name:         "optional invalid token",
+			opts:         []JWTAuthOption{WithOptionalAuth()},
+			method:       testMethod,
+			token:        "invalid",
+			expectedCode: codes.Unauthenticated,
+		}, {
+			name:   "skip health method",
+			method: "/grpc.health.v1.Health/Check",
+		}, {
+			name:         "custom skip methods",
+			opts:         []JWTAuthOption{WithSkipMethods(testMethod)},
+			method:       "/grpc.health.v1.Health/Check",
+			expectedCode: codes.Unauthenticated,
+		}, {
+			name:            "role",
+			method:          testMethod,
+			role:            testRole,
+			token:           token,
+			expectedSubject: testSubject,
+		}, {
+			name:         "missing role",
+			method:       testMethod,
+			role:         testRole,
+			token:        tokenWithoutRole,
+			expectedCode: codes.PermissionDenied,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			auth := NewJWTAuth(publicKey, testIssuer, testAudience, false, true, test.opts...)
+
+			ctx := context.Background()
+			if test.token != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(HeaderAuthorization, "Bearer "+test.token))
+			}
+
+			unary := auth.Auth
+			stream := auth.Stream
+			if test.role != "" {
+				unary = auth.AuthRole(test.role)
+				stream = auth.StreamRole(test.role)
+			}
+
+			var handlerCtx context.Context
+			_, err := unary(ctx, nil, &grpc.UnaryServerInfo{FullMethod: test.method},
+				func(ctx context.Context, _ any) (any, error) {
+					handlerCtx = ctx
+					return nil, nil
+				})
+			require.Equal(t, test.expectedCode, status.Code(err))
+
+			streamErr := stream(nil, testServerStream{ctx: ctx}, &grpc.StreamServerInfo{FullMethod: test.method},
+				func(_ any, ss grpc.ServerStream) error {
+					require.Equal(t, test.expectedSubject, econtext.ClientInfo(ss.Context()).Subject)
+					return nil
+				})
+			require.Equal(t, test.expectedCode, status.Code(streamErr))
+
+			if err == nil {
+				require.Equal(t, test.expectedSubject, econtext.ClientInfo(handlerCtx).Subject)
+				if test.expectedSubject != "" {
+					require.Equal(t, test.token, econtext.AuthToken(handlerCtx))
+				}
+			}
+		})
+	}
+}
+
+type failingRevocationStore struct {
+	ejwt.RevocationStore
+}
+
+func (failingRevocationStore) IsRevoked(context.Context, ejwt.Claims) (bool, error) {
+	return false, errors.New("revocation store down")
+}
+
+func TestJWTAuthRevocationStoreFailed(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	jwtGenerator := ejwt.NewJWTGenerator(privateKey, testIssuer, testAudience, time.Minute)
+	token, err := jwtGenerator.GenerateToken(testSubject)
+	require.NoError(t, err)
+
+	verifier := ejwt.NewVerifier(publicKey, testIssuer, testAudience, ejwt.WithRevocationStore(failingRevocationStore{}))
+	auth := NewJWTAuthVerifier(verifier, false)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(HeaderAuthorization, "Bearer "+token))
+	_, err = auth.Auth(ctx, nil, &grpc.UnaryServerInfo{FullMethod: testMethod},
+		func(context.Context, any) (any, error) { return nil, nil })
+	require.Equal(t, codes.Internal, status.Code(err))
+	require.NotContains(t, err.Error(), "revocation store down")
+}
+
+func TestClientServiceAuth(t *testing.T) {
+	_, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	jwtGenerator := ejwt.NewJWTGenerator(privateKey, testIssuer, "", time.Minute)
+	clientAuth := ClientServiceAuth(ejwt.NewServiceTokenSource(&jwtGenerator, testSubject), testAudience)
+
+	authorization := func(ctx context.Context) string {
+		var value string
+		err := clientAuth(ctx, testMethod, nil, nil, nil,
+			func(ctx context.Context, _ string, _, _ any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+				md, _ := metadata.FromOutgoingContext(ctx)
+				value = md.Get(HeaderAuthorization)[0]
+				return nil
+			})
+		require.NoError(t, err)
+		return value
+	}
+
+	require.Equal(t, "bearer user-token", authorization(econtext.SetAuthToken(context.Background(), "user-token")))
+
+	parts := strings.SplitN(authorization(context.Background()), " ", 2)
+	require.Len(t, parts, 2)
+
+	var claims ejwt.Claims
+	require.NoError(t, ejwt.NewVerifier(nil, "", "").ParseUnverified(parts[1], &claims))
+	require.Equal(t, testSubject, claims.Subject)
+	require.Equal(t, []string{testAudience}, []string(claims.Audience))
+}
@@ -0,0 +1,64 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/stepanbukhtii/easy-tools/authz"
+)
+
+// MethodPolicies maps grpc.UnaryServerInfo.FullMethod to policy
+type MethodPolicies map[string]authz.Policy
+
+type Authorization struct {
+	authorizer    *authz.Authorizer
+	policies      MethodPolicies
+	defaultPolicy authz.Policy
+}
+
+// NewAuthorization creates authorization interceptors, methods missing in policies use
+// defaultPolicy, nil default policy allows them.
+func NewAuthorization(authorizer *authz.Authorizer, policies MethodPolicies, defaultPolicy authz.Policy) *Authorization {
+	return &Authorization{
+		authorizer:    authorizer,
+		policies:      policies,
+		defaultPolicy: defaultPolicy,
+	}
+}
+
+func (a Authorization) Unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if err := a.authorize(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+func (a Authorization) Stream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := a.authorize(ss.Context(), info.FullMethod); err != nil {
+		return err
+	}
+
+	return handler(srv, ss)
+}
+
+func (a Authorization) authorize(ctx context.Context, method string) error {
+	policy, ok := a.policies[method]
+	if !ok {
+		policy = a.defaultPolicy
+	}
+
+	err := a.authorizer.AuthorizeContext(ctx, policy)
+	switch {
+	case errors.Is(err, authz.ErrUnauthenticated):
+		return status.Error(codes.Unauthenticated, err.Error())
+	case err != nil:
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,275 @@
+package interceptor
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const meterName = "github.com/stepanbukhtii/easy-tools/grpc/interceptor"
+
+// DefaultCircuitBreakerConfig opens the circuit after 5 consecutive failures for 30 seconds.
+var DefaultCircuitBreakerConfig = CircuitBreakerConfig{
+	FailureThreshold: 5,
+	OpenTimeout:      30 * time.Second,
+	HalfOpenMaxCalls: 1,
+	FailureCodes: []codes.Code{
+		codes.Unavailable,
+		codes.DeadlineExceeded,
+		codes.ResourceExhausted,
+		codes.Internal,
+		codes.Unknown,
+	},
+}
+
+type CircuitBreakerConfig struct {
+	// FailureThreshold is the number of consecutive failures opening the circuit
+	FailureThreshold int
+	// OpenTimeout is how long the circuit stays open before probing
+	OpenTimeout time.Duration
+	// HalfOpenMaxCalls probing calls have to succeed to close the circuit again
+	HalfOpenMaxCalls int
+	FailureCodes     []codes.Code
+}
+
+type CircuitState int64
+
+const (
+	CircuitClosed CircuitState = iota
+	CircuitOpen
+	CircuitHalfOpen
+)
+
+func (s CircuitState) severity() int {
+	switch s {
+	case CircuitOpen:
+		return 2
+	case CircuitHalfOpen:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half_open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreaker rejects calls with codes.Unavailable while open. State is reported as
+// rpc.client.circuit_breaker.state gauge and transitions as
+// rpc.client.circuit_breaker.transitions counter with circuit_breaker.name attribute.
+type CircuitBreaker struct {
+	name   string
+	config CircuitBreakerConfig
+
+	transitions metric.Int64Counter
+
+	mu            sync.Mutex
+	state         CircuitState
+	failures      int
+	openedAt      time.Time
+	halfOpenCalls int
+	halfOpenOK    int
+}
+
+// circuitBreakers are observed by the single state gauge callback, so breakers are released
+// on Close and breakers with the same name report one series.
+var (
+	circuitBreakersMu sync.Mutex
+	circuitBreakers   = make(map[*CircuitBreaker]struct{})
+	stateGaugeOnce    sync.Once
+	stateGaugeErr     error
+)
+
+// NewCircuitBreaker creates circuit breaker reported in metrics until Close.
+func NewCircuitBreaker(name string, config CircuitBreakerConfig) (*CircuitBreaker, error) {
+	cb := &CircuitBreaker{
+		name:   name,
+		config: config,
+	}
+
+	var err error
+	cb.transitions, err = otel.Meter(meterName).Int64Counter("rpc.client.circuit_breaker.transitions",
+		metric.WithDescription("Circuit breaker state transitions"))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := registerStateGauge(); err != nil {
+		return nil, err
+	}
+
+	circuitBreakersMu.Lock()
+	circuitBreakers[cb] = struct{}{}
+	circuitBreakersMu.Unlock()
+
+	return cb, nil
+}
+
+// Close stops reporting the circuit breaker state.
+func (cb *CircuitBreaker) Close() {
+	circuitBreakersMu.Lock()
+	delete(circuitBreakers, cb)
+	circuitBreakersMu.Unlock()
+}
+
+func registerStateGauge() error {
+	stateGaugeOnce.Do(func() {
+		_, stateGaugeErr = otel.Meter(meterName).Int64ObservableGauge("rpc.client.circuit_breaker.state",
+			metric.WithDescription("Circuit breaker state: 0 closed, 1 open, 2 half open"),
+			metric.WithInt64Callback(observeCircuitBreakers))
+	})
+	return stateGaugeErr
+}
+
+// observeCircuitBreakers reports the worst state of breakers with the same name
+func observeCircuitBreakers(_ context.Context, o metric.Int64Observer) error {
+	circuitBreakersMu.Lock()
+	states := make(map[string]CircuitState, len(circuitBreakers))
+	for cb := range circuitBreakers {
+		state := cb.State()
+		if current, ok := states[cb.name]; !ok || state.severity() > current.severity() {
+			states[cb.name] = state
+		}
+	}
+	circuitBreakersMu.Unlock()
+
+	for name, state := range states {
+		o.Observe(int64(state), metric.WithAttributes(attribute.String("circuit_breaker.name", name)))
+	}
+
+	return nil
+}
+
+func (cb *CircuitBreaker) State() CircuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	return cb.currentState()
+}
+
+func (cb *CircuitBreaker) Unary(
+	ctx context.Context,
+	method string,
+	req, reply any,
+	cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker,
+	opts ...grpc.CallOption,
+) error {
+	if err := cb.allow(ctx); err != nil {
+		return err
+	}
+
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	cb.record(ctx, err)
+
+	return err
+}
+
+// Stream applies circuit breaker to opening of streams
+func (cb *CircuitBreaker) Stream(
+	ctx context.Context,
+	desc *grpc.StreamDesc,
+	cc *grpc.ClientConn,
+	method string,
+	streamer grpc.Streamer,
+	opts ...grpc.CallOption,
+) (grpc.ClientStream, error) {
+	if err := cb.allow(ctx); err != nil {
+		return nil, err
+	}
+
+	cs, err := streamer(ctx, desc, cc, method, opts...)
+	cb.record(ctx, err)
+
+	return cs, err
+}
+
+func (cb *CircuitBreaker) allow(ctx context.Context) error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.currentState() {
+	case CircuitOpen:
+		return status.Errorf(codes.Unavailable, "circuit breaker %s is open", cb.name)
+	case CircuitHalfOpen:
+		if cb.state == CircuitOpen {
+			cb.transition(ctx, CircuitHalfOpen)
+		}
+		if cb.halfOpenCalls >= max(cb.config.HalfOpenMaxCalls, 1) {
+			return status.Errorf(codes.Unavailable, "circuit breaker %s is half open", cb.name)
+		}
+		cb.halfOpenCalls++
+	}
+
+	return nil
+}
+
+func (cb *CircuitBreaker) record(ctx context.Context, err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	failed := err != nil && slices.Contains(cb.config.FailureCodes, status.Code(err))
+
+	switch cb.state {
+	case CircuitClosed:
+		if !failed {
+			cb.failures = 0
+			return
+		}
+		cb.failures++
+		if cb.failures >= cb.config.FailureThreshold {
+			cb.transition(ctx, CircuitOpen)
+		}
+	case CircuitHalfOpen:
+		if failed {
+			cb.transition(ctx, CircuitOpen)
+			return
+		}
+		cb.halfOpenOK++
+		if cb.halfOpenOK >= max(cb.config.HalfOpenMaxCalls, 1) {
+			cb.transition(ctx, CircuitClosed)
+		}
+	}
+}
+
+// currentState reports open circuit with elapsed timeout as half open
+func (cb *CircuitBreaker) currentState() CircuitState {
+	if cb.state == CircuitOpen && time.Since(cb.openedAt) >= cb.config.OpenTimeout {
+		return CircuitHalfOpen
+	}
+	return cb.state
+}
+
+func (cb *CircuitBreaker) transition(ctx context.Context, state CircuitState) {
+	cb.state = state
+	cb.failures = 0
+	cb.halfOpenCalls = 0
+	cb.halfOpenOK = 0
+
+	if state == CircuitOpen {
+		cb.openedAt = time.Now()
+	}
+
+	cb.transitions.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("circuit_breaker.name", cb.name),
+		attribute.String("circuit_breaker.state", state.String()),
+	))
+}
@@ -0,0 +1,128 @@
+package interceptor
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/stepanbukhtii/easy-tools/elog"
+)
+
+// MethodTimeouts maps full method, or service prefix ending with "/", to call timeout
+type MethodTimeouts map[string]time.Duration
+
+type Timeout struct {
+	defaultTimeout time.Duration
+	timeouts       MethodTimeouts
+}
+
+// NewTimeout creates interceptor applying timeout to calls without deadline, methods
+// missing in timeouts use defaultTimeout, zero timeout leaves the call without deadline.
+func NewTimeout(defaultTimeout time.Duration, timeouts MethodTimeouts) *Timeout {
+	return &Timeout{
+		defaultTimeout: defaultTimeout,
+		timeouts:       timeouts,
+	}
+}
+
+func (t *Timeout) Unary(
+	ctx context.Context,
+	method string,
+	req, reply any,
+	cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker,
+	opts ...grpc.CallOption,
+) error {
+	if _, ok := ctx.Deadline(); !ok {
+		timeout, ok := matchMethod(t.timeouts, method)
+		if !ok {
+			timeout = t.defaultTimeout
+		}
+
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+	}
+
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+// MinDeadline rejects calls which remaining deadline is below minimum, the caller would
+// give up before the work is done
+func MinDeadline(minimum time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := checkDeadline(ctx, minimum); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamMinDeadline is the stream variant of MinDeadline
+func StreamMinDeadline(minimum time.Duration) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := checkDeadline(ss.Context(), minimum); err != nil {
+			return err
+		}
+
+		return handler(srv, ss)
+	}
+}
+
+func checkDeadline(ctx context.Context, minimum time.Duration) error {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return nil
+	}
+
+	if remaining := time.Until(deadline); remaining < minimum {
+		return status.Errorf(codes.DeadlineExceeded, "remaining deadline %s is below %s", remaining, minimum)
+	}
+
+	return nil
+}
+
+// deadlineAttrs returns log attributes with the deadline budget the call had and the
+// share of it consumed
+func deadlineAttrs(budget, duration time.Duration) []slog.Attr {
+	if budget <= 0 {
+		return nil
+	}
+
+	return []slog.Attr{
+		slog.Duration(elog.RPCDeadlineBudget, budget),
+		slog.Float64(elog.RPCDeadlineConsumed, float64(duration)/float64(budget)),
+	}
+}
+
+func deadlineBudget(ctx context.Context) time.Duration {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0
+	}
+	return time.Until(deadline)
+}
+
+// matchMethod returns value of full method or of its service prefix ending with "/"
+func matchMethod[T any](values map[string]T, method string) (T, bool) {
+	if value, ok := values[method]; ok {
+		return value, true
+	}
+
+	for prefix, value := range values {
+		if strings.HasSuffix(prefix, "/") && strings.HasPrefix(method, prefix) {
+			return value, true
+		}
+	}
+
+	var zero T
+	return zero, false
+}
@@ -0,0 +1,71 @@
+package interceptor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestTimeout(t *testing.T) {
+	timeout := NewTimeout(time.Minute, MethodTimeouts{
+		"/test.v1.Service/":     time.Second,
+		"/test.v1.Other/Method": 0,
+	})
+
+	remaining := func(ctx context.Context, method string) time.Duration {
+		var result time.Duration
+		err := timeout.Unary(ctx, method, nil, nil, nil,
+			func(ctx context.Context, _ string, _, _ any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+				if deadline, ok := ctx.Deadline(); ok {
+					result = time.Until(deadline)
+				}
+				return nil
+			})
+		require.NoError(t, err)
+		return result
+	}
+
+	require.InDelta(t, time.Second, remaining(context.Background(), testMethod), float64(100*time.Millisecond))
+	require.InDelta(t, time.Minute, remaining(context.Background(), "/test.v1.Unknown/Method"), float64(100*time.Millisecond))
+	require.Zero(t, remaining(context.Background(), "/test.v1.Other/Method"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.InDelta(t, 5*time.Second, remaining(ctx, testMethod), float64(100*time.Millisecond))
+}
+
+func TestMinDeadline(t *testing.T) {
+	handler := func(context.Context, any) (any, error) { return nil, nil }
+	info := &grpc.UnaryServerInfo{FullMethod: testMethod}
+	interceptor := MinDeadline(100 * time.Millisecond)
+
+	_, err := interceptor(context.Background(), nil, info, handler)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = interceptor(ctx, nil, info, handler)
+	require.NoError(t, err)
+
+	shortCtx, shortCancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer shortCancel()
+	_, err = interceptor(shortCtx, nil, info, handler)
+	require.Equal(t, codes.DeadlineExceeded, status.Code(err))
+
+	err = StreamMinDeadline(100*time.Millisecond)(nil, testServerStream{ctx: shortCtx}, &grpc.StreamServerInfo{},
+		func(any, grpc.ServerStream) error { return nil })
+	require.Equal(t, codes.DeadlineExceeded, status.Code(err))
+}
+
+func TestDeadlineAttrs(t *testing.T) {
+	require.Nil(t, deadlineAttrs(0, time.Second))
+
+	attrs := deadlineAttrs(time.Second, 250*time.Millisecond)
+	require.Len(t, attrs, 2)
+	require.Equal(t, 0.25, attrs[1].Value.Float64())
+}
@@ -0,0 +1,270 @@
+package interceptor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"slices"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+
+	"github.com/stepanbukhtii/easy-tools/errx"
+	"github.com/stepanbukhtii/easy-tools/rest/api"
+)
+
+// ErrorDomain is set to errdetails.ErrorInfo.Domain of converted errors.
+var ErrorDomain = ""
+
+// errorDataKey holds JSON of errx.Error.ResponseData in ErrorInfo.Metadata when it is
+// not a field to description map.
+const errorDataKey = "data"
+
+// StatusError is returned by client interceptors for failed calls, it unwraps to the api
+// sentinel matching the status so errors.Is works across service boundaries.
+type StatusError struct {
+	Status       *status.Status
+	Sentinel     error
+	ResponseData any
+}
+
+func (e *StatusError) Error() string {
+	return e.Status.Message()
+}
+
+func (e *StatusError) Unwrap() error {
+	return e.Sentinel
+}
+
+func (e *StatusError) GRPCStatus() *status.Status {
+	return e.Status
+}
+
+// ErrorCode maps error to gRPC code with the taxonomy of api.ErrorStatusCode.
+func ErrorCode(err error) codes.Code {
+	var grpcStatus interface{ GRPCStatus() *status.Status }
+
+	switch {
+	case err == nil:
+		return codes.OK
+	case errors.As(err, &grpcStatus):
+		return grpcStatus.GRPCStatus().Code()
+	case errors.Is(err, context.Canceled):
+		return codes.Canceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return codes.DeadlineExceeded
+	case errors.Is(err, api.ErrBadRequest), errors.Is(err, api.ErrValidation):
+		return codes.InvalidArgument
+	case errors.Is(err, api.ErrUnauthorized):
+		return codes.Unauthenticated
+	case errors.Is(err, api.ErrForbidden):
+		return codes.PermissionDenied
+	case errors.Is(err, api.ErrNotFound):
+		return codes.NotFound
+	case errors.Is(err, api.ErrConflict):
+		return codes.AlreadyExists
+	case errors.Is(err, api.ErrTooManyRequests):
+		return codes.ResourceExhausted
+	default:
+		return codes.Internal
+	}
+}
+
+// ErrorStatus converts error to status, errx.Error.ResponseData is attached as errdetails:
+// field violations of map[string]string for invalid argument, ErrorInfo metadata otherwise.
+func ErrorStatus(err error) *status.Status {
+	if err == nil {
+		return nil
+	}
+
+	if grpcStatus, ok := status.FromError(err); ok {
+		return grpcStatus
+	}
+
+	code := ErrorCode(err)
+	grpcStatus := status.New(code, err.Error())
+
+	errorInfo := &errdetails.ErrorInfo{
+		Reason: errorReason(err),
+		Domain: ErrorDomain,
+	}
+
+	var details []protoadapt.MessageV1
+	var errorWithData errx.Error
+	if errors.As(err, &errorWithData) && errorWithData.ResponseData != nil {
+		fields, isFields := errorWithData.ResponseData.(map[string]string)
+		switch {
+		case isFields && code == codes.InvalidArgument:
+			badRequest := &errdetails.BadRequest{}
+			for _, field := range slices.Sorted(maps.Keys(fields)) {
+				badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+					Field:       field,
+					Description: fields[field],
+				})
+			}
+			details = append(details, badRequest)
+		case isFields:
+			errorInfo.Metadata = fields
+		default:
+			if data, err := json.Marshal(errorWithData.ResponseData); err == nil {
+				errorInfo.Metadata = map[string]string{errorDataKey: string(data)}
+			}
+		}
+	}
+
+	details = append(details, errorInfo)
+
+	withDetails, detailsErr := grpcStatus.WithDetails(details...)
+	if detailsErr != nil {
+		return grpcStatus
+	}
+
+	return withDetails
+}
+
+// StatusToError converts status error back to StatusError, other errors are returned as is.
+func StatusToError(err error) error {
+	grpcStatus, ok := status.FromError(err)
+	if !ok || grpcStatus.Code() == codes.OK {
+		return err
+	}
+
+	statusErr := &StatusError{
+		Status:   grpcStatus,
+		Sentinel: codeSentinel(grpcStatus.Code()),
+	}
+
+	for _, detail := range grpcStatus.Details() {
+		switch d := detail.(type) {
+		case *errdetails.ErrorInfo:
+			if sentinel := reasonSentinel(d.Reason); sentinel != nil {
+				statusErr.Sentinel = sentinel
+			}
+			if data, ok := d.Metadata[errorDataKey]; ok && len(d.Metadata) == 1 {
+				statusErr.ResponseData = json.RawMessage(data)
+			} else if len(d.Metadata) > 0 {
+				statusErr.ResponseData = d.Metadata
+			}
+		case *errdetails.BadRequest:
+			fields := make(map[string]string, len(d.FieldViolations))
+			for _, violation := range d.FieldViolations {
+				fields[violation.Field] = violation.Description
+			}
+			statusErr.ResponseData = fields
+		}
+	}
+
+	return statusErr
+}
+
+// ServerErrors converts handler errors to gRPC status
+func ServerErrors(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	resp, err := handler(ctx, req)
+	if err != nil {
+		return resp, ErrorStatus(err).Err()
+	}
+
+	return resp, nil
+}
+
+// StreamServerErrors converts stream handler errors to gRPC status
+func StreamServerErrors(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := handler(srv, ss); err != nil {
+		return ErrorStatus(err).Err()
+	}
+
+	return nil
+}
+
+// ClientErrors converts gRPC status of failed calls to StatusError
+func ClientErrors(
+	ctx context.Context,
+	method string,
+	req, reply any,
+	cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker,
+	opts ...grpc.CallOption,
+) error {
+	return StatusToError(invoker(ctx, method, req, reply, cc, opts...))
+}
+
+// StreamClientErrors converts gRPC status of failed streams to StatusError
+func StreamClientErrors(
+	ctx context.Context,
+	desc *grpc.StreamDesc,
+	cc *grpc.ClientConn,
+	method string,
+	streamer grpc.Streamer,
+	opts ...grpc.CallOption,
+) (grpc.ClientStream, error) {
+	cs, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		return nil, StatusToError(err)
+	}
+
+	return &errorsClientStream{ClientStream: cs}, nil
+}
+
+type errorsClientStream struct {
+	grpc.ClientStream
+}
+
+func (s *errorsClientStream) SendMsg(m any) error {
+	return StatusToError(s.ClientStream.SendMsg(m))
+}
+
+func (s *errorsClientStream) RecvMsg(m any) error {
+	return StatusToError(s.ClientStream.RecvMsg(m))
+}
+
+var sentinels = []error{
+	api.ErrBadRequest,
+	api.ErrValidation,
+	api.ErrUnauthorized,
+	api.ErrForbidden,
+	api.ErrNotFound,
+	api.ErrConflict,
+	api.ErrTooManyRequests,
+	api.ErrInternalServerError,
+}
+
+func errorReason(err error) string {
+	for _, sentinel := range sentinels {
+		if errors.Is(err, sentinel) {
+			return sentinel.Error()
+		}
+	}
+	return ""
+}
+
+func reasonSentinel(reason string) error {
+	for _, sentinel := range sentinels {
+		if sentinel.Error() == reason {
+			return sentinel
+		}
+	}
+	return nil
+}
+
+func codeSentinel(code codes.Code) error {
+	switch code {
+	case codes.InvalidArgument, codes.OutOfRange, codes.FailedPrecondition:
+		return api.ErrBadRequest
+	case codes.Unauthenticated:
+		return api.ErrUnauthorized
+	case codes.PermissionDenied:
+		return api.ErrForbidden
+	case codes.NotFound:
+		return api.ErrNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return api.ErrConflict
+	case codes.ResourceExhausted:
+		return api.ErrTooManyRequests
+	default:
+		return api.ErrInternalServerError
+	}
+}
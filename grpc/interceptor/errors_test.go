@@ -0,0 +1,106 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/stepanbukhtii/easy-tools/errx"
+	"github.com/stepanbukhtii/easy-tools/rest/api"
+)
+
+func TestErrorStatus(t *testing.T) {
+	tests := []struct {
+		name                 string
+		err                  error
+		expectedCode         codes.Code
+		expectedSentinel     error
+		expectedResponseData any
+	}{
+		{
+			name:             "not found",
+			err:              fmt.Errorf("user: %w", api.ErrNotFound),
+			expectedCode:     codes.NotFound,
+			expectedSentinel: api.ErrNotFound,
+		}, {
+			name:             "conflict",
+			err:              errx.Wrap(api.ErrConflict, "email"),
+			expectedCode:     codes.AlreadyExists,
+			expectedSentinel: api.ErrConflict,
+		}, {
+			name: "validation with fields",
+			err: errx.Wrap(api.ErrValidation, "create user").WithResponseData(map[string]string{
+				"email": "required",
+				"name":  "too long",
+			}),
+			expectedCode:     codes.InvalidArgument,
+			expectedSentinel: api.ErrValidation,
+			expectedResponseData: map[string]string{
+				"email": "required",
+				"name":  "too long",
+			},
+		}, {
+			name:                 "forbidden with metadata",
+			err:                  errx.Wrap(api.ErrForbidden, "order").WithResponseData(map[string]string{"owner": "other"}),
+			expectedCode:         codes.PermissionDenied,
+			expectedSentinel:     api.ErrForbidden,
+			expectedResponseData: map[string]string{"owner": "other"},
+		}, {
+			name:             "too many requests",
+			err:              api.ErrTooManyRequests,
+			expectedCode:     codes.ResourceExhausted,
+			expectedSentinel: api.ErrTooManyRequests,
+		}, {
+			name:             "unknown error",
+			err:              errors.New("boom"),
+			expectedCode:     codes.Internal,
+			expectedSentinel: api.ErrInternalServerError,
+		}, {
+			name:             "status",
+			err:              status.Error(codes.Unauthenticated, "no token"),
+			expectedCode:     codes.Unauthenticated,
+			expectedSentinel: api.ErrUnauthorized,
+		}, {
+			name:             "deadline",
+			err:              context.DeadlineExceeded,
+			expectedCode:     codes.DeadlineExceeded,
+			expectedSentinel: api.ErrInternalServerError,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			grpcStatus := ErrorStatus(test.err)
+			require.Equal(t, test.expectedCode, grpcStatus.Code())
+			require.Equal(t, status.Convert(test.err).Message(), grpcStatus.Message())
+
+			// simulate the wire
+			wire, err := proto.Marshal(grpcStatus.Proto())
+			require.NoError(t, err)
+
+			received := status.New(codes.OK, "").Proto()
+			require.NoError(t, proto.Unmarshal(wire, received))
+
+			clientErr := StatusToError(status.FromProto(received).Err())
+			require.ErrorIs(t, clientErr, test.expectedSentinel)
+			require.Equal(t, test.expectedCode, status.Code(clientErr))
+			require.Equal(t, grpcStatus.Message(), clientErr.Error())
+
+			var statusErr *StatusError
+			require.ErrorAs(t, clientErr, &statusErr)
+			if test.expectedResponseData != nil {
+				require.Equal(t, test.expectedResponseData, statusErr.ResponseData)
+			} else {
+				require.Nil(t, statusErr.ResponseData)
+			}
+		})
+	}
+
+	require.NoError(t, StatusToError(nil))
+}
@@ -2,31 +2,91 @@ package interceptor
 
 import (
 	"context"
-	"encoding/json"
 	"log/slog"
+	"math/rand/v2"
 	"time"
 
 	semconv "go.opentelemetry.io/otel/semconv/v1.40.0"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/status"
 
 	"github.com/stepanbukhtii/easy-tools/econtext"
 	"github.com/stepanbukhtii/easy-tools/elog"
 )
 
+// LogConfig sets how calls are logged, failed calls are always logged at error level
+// with request body.
+type LogConfig struct {
+	// Level of successful calls, use slog.LevelDebug to hide noisy methods.
+	Level slog.Level
+	// SampleRate is the fraction of successful calls logged, values outside (0, 1) log every call.
+	SampleRate float64
+	// ResponseBody adds response of successful unary calls.
+	ResponseBody bool
+}
+
+// MethodLogConfigs maps full method, or service prefix ending with "/", to log config
+type MethodLogConfigs map[string]LogConfig
+
+// DefaultLogger is used by ServerLogger, ClientLogger and their stream variants, it logs
+// every call at info level without response body. Replace it to configure default interceptors.
+var DefaultLogger = NewLogger(LogConfig{Level: slog.LevelInfo}, nil)
+
+type Logger struct {
+	defaultConfig LogConfig
+	configs       MethodLogConfigs
+}
+
+// NewLogger creates logging interceptors, methods missing in configs use defaultConfig.
+func NewLogger(defaultConfig LogConfig, configs MethodLogConfigs) *Logger {
+	return &Logger{
+		defaultConfig: defaultConfig,
+		configs:       configs,
+	}
+}
+
 func ServerLogger(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	return DefaultLogger.UnaryServer(ctx, req, info, handler)
+}
+
+func ClientLogger(
+	ctx context.Context,
+	method string,
+	req, reply any,
+	cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker,
+	opts ...grpc.CallOption,
+) error {
+	return DefaultLogger.UnaryClient(ctx, method, req, reply, cc, invoker, opts...)
+}
+
+func StreamServerLogger(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return DefaultLogger.StreamServer(srv, ss, info, handler)
+}
+
+func StreamClientLogger(
+	ctx context.Context,
+	desc *grpc.StreamDesc,
+	cc *grpc.ClientConn,
+	method string,
+	streamer grpc.Streamer,
+	opts ...grpc.CallOption,
+) (grpc.ClientStream, error) {
+	return DefaultLogger.StreamClient(ctx, desc, cc, method, streamer, opts...)
+}
+
+func (l *Logger) UnaryServer(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	budget := deadlineBudget(ctx)
+
 	start := time.Now()
 	resp, err := handler(ctx, req)
 	duration := time.Now().UTC().Sub(start)
 
-	if err := logRequest(ctx, info.FullMethod, duration, req, err); err != nil {
-		return resp, err
-	}
+	l.logRequest(ctx, info.FullMethod, duration, req, resp, err, deadlineAttrs(budget, duration)...)
 
 	return resp, err
 }
 
-func ClientLogger(
+func (l *Logger) UnaryClient(
 	ctx context.Context,
 	method string,
 	req, reply any,
@@ -38,48 +98,157 @@ func ClientLogger(
 	err := invoker(ctx, method, req, reply, cc, opts...)
 	duration := time.Now().UTC().Sub(start)
 
-	if err := logRequest(ctx, method, duration, req, err); err != nil {
-		return err
-	}
+	l.logRequest(ctx, method, duration, req, reply, err)
 
 	return err
 }
 
-func logRequest(ctx context.Context, method string, duration time.Duration, req any, err error) error {
-	var requestData []byte
-	if req != nil {
-		var err error
-		requestData, err = json.Marshal(req)
-		if err != nil {
-			return err
+func (l *Logger) StreamServer(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	stats := &streamStats{}
+	budget := deadlineBudget(ss.Context())
+
+	start := time.Now()
+	err := handler(srv, &statsServerStream{ServerStream: ss, stats: stats})
+	duration := time.Now().UTC().Sub(start)
+
+	l.logStream(ss.Context(), info.FullMethod, duration, stats, err, deadlineAttrs(budget, duration)...)
+
+	return err
+}
+
+func (l *Logger) StreamClient(
+	ctx context.Context,
+	desc *grpc.StreamDesc,
+	cc *grpc.ClientConn,
+	method string,
+	streamer grpc.Streamer,
+	opts ...grpc.CallOption,
+) (grpc.ClientStream, error) {
+	start := time.Now()
+	stats := &streamStats{}
+
+	cs, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		l.logStream(ctx, method, time.Now().UTC().Sub(start), stats, err)
+		return nil, err
+	}
+
+	return &statsClientStream{
+		ClientStream:  cs,
+		stats:         stats,
+		serverStreams: desc.ServerStreams,
+		finish: func(err error) {
+			l.logStream(ctx, method, time.Now().UTC().Sub(start), stats, err)
+		},
+	}, nil
+}
+
+func (l *Logger) config(method string) LogConfig {
+	if config, ok := matchMethod(l.configs, method); ok {
+		return config
+	}
+	return l.defaultConfig
+}
+
+// logger returns nil when the call is not sampled or its level is disabled, so bodies
+// are not marshaled for discarded records
+func (l *Logger) logger(ctx context.Context, config LogConfig, err error) (*slog.Logger, slog.Level) {
+	if err != nil {
+		logger := econtext.Logger(ctx)
+		if !logger.Enabled(ctx, slog.LevelError) {
+			logger = slog.Default()
 		}
+		return logger, slog.LevelError
+	}
+
+	if config.SampleRate > 0 && config.SampleRate < 1 && rand.Float64() >= config.SampleRate {
+		return nil, config.Level
 	}
 
 	logger := econtext.Logger(ctx)
-	if !logger.Enabled(ctx, slog.LevelError) {
-		logger = slog.Default()
+	if !logger.Enabled(ctx, config.Level) {
+		return nil, config.Level
 	}
 
-	logger = logger.With(
-		slog.String(string(semconv.RPCSystemNameKey), "grpc"),
-		slog.String(string(semconv.RPCMethodKey), method),
-		slog.Duration(elog.RPCRequestDuration, duration),
-	)
+	return logger, config.Level
+}
 
-	if len(requestData) > 0 {
-		logger = logger.With(slog.String(elog.RPCRequestBodyContent, string(requestData)))
+func (l *Logger) logRequest(
+	ctx context.Context,
+	method string,
+	duration time.Duration,
+	req, resp any,
+	err error,
+	attrs ...slog.Attr,
+) {
+	config := l.config(method)
+
+	logger, level := l.logger(ctx, config, err)
+	if logger == nil {
+		return
 	}
 
-	if errStatus, ok := status.FromError(err); ok {
-		logger = logger.With(slog.String(string(semconv.RPCResponseStatusCodeKey), errStatus.Code().String()))
+	logger = rpcLogger(logger, method, duration, err, attrs...)
+
+	if requestData := elog.DefaultRedactor.Value(req); requestData != "" {
+		logger = logger.With(slog.String(elog.RPCRequestBodyContent, requestData))
 	}
 
-	switch {
-	case err != nil:
+	if err != nil {
 		logger.With(elog.Err(err)).ErrorContext(ctx, "gRPC request")
-	default:
-		logger.InfoContext(ctx, "gRPC request")
+		return
 	}
 
-	return nil
+	if config.ResponseBody {
+		if responseData := elog.DefaultRedactor.Value(resp); responseData != "" {
+			logger = logger.With(slog.String(elog.RPCResponseBodyContent, responseData))
+		}
+	}
+
+	logger.Log(ctx, level, "gRPC request")
+}
+
+func (l *Logger) logStream(
+	ctx context.Context,
+	method string,
+	duration time.Duration,
+	stats *streamStats,
+	err error,
+	attrs ...slog.Attr,
+) {
+	logger, level := l.logger(ctx, l.config(method), err)
+	if logger == nil {
+		return
+	}
+
+	logger = rpcLogger(logger, method, duration, err, attrs...).With(
+		slog.Int64(elog.RPCStreamMessagesSent, stats.messagesSent.Load()),
+		slog.Int64(elog.RPCStreamMessagesReceived, stats.messagesReceived.Load()),
+		slog.Int64(elog.RPCStreamBytesSent, stats.bytesSent.Load()),
+		slog.Int64(elog.RPCStreamBytesReceived, stats.bytesReceived.Load()),
+	)
+
+	if err != nil {
+		logger.With(elog.Err(err)).ErrorContext(ctx, "gRPC stream")
+		return
+	}
+
+	logger.Log(ctx, level, "gRPC stream")
+}
+
+func rpcLogger(logger *slog.Logger, method string, duration time.Duration, err error, attrs ...slog.Attr) *slog.Logger {
+	return logger.With(
+		slog.String(string(semconv.RPCSystemNameKey), "grpc"),
+		slog.String(string(semconv.RPCMethodKey), method),
+		slog.Duration(elog.RPCRequestDuration, duration),
+		slog.String(string(semconv.RPCResponseStatusCodeKey), ErrorCode(err).String()),
+	).With(attrsToAny(attrs)...)
+}
+
+func attrsToAny(attrs []slog.Attr) []any {
+	args := make([]any, len(attrs))
+	for i, attr := range attrs {
+		args[i] = attr
+	}
+	return args
 }
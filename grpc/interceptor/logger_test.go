@@ -0,0 +1,93 @@
+package interceptor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/stepanbukhtii/easy-tools/econtext"
+	"github.com/stepanbukhtii/easy-tools/elog"
+)
+
+func TestLogger_UnaryServer(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   LogConfig
+		configs  MethodLogConfigs
+		err      error
+		expected map[string]any
+	}{
+		{
+			name:   "request body",
+			config: LogConfig{},
+			expected: map[string]any{
+				"level":                     "INFO",
+				elog.RPCRequestBodyContent:  `{"service":"service"}`,
+				elog.RPCResponseBodyContent: nil,
+			},
+		},
+		{
+			name:   "response body",
+			config: LogConfig{ResponseBody: true},
+			expected: map[string]any{
+				"level":                     "INFO",
+				elog.RPCRequestBodyContent:  `{"service":"service"}`,
+				elog.RPCResponseBodyContent: `{"status":"SERVING"}`,
+			},
+		},
+		{
+			name:     "method level disabled",
+			config:   LogConfig{ResponseBody: true},
+			configs:  MethodLogConfigs{"/test.v1.Service/": {Level: slog.LevelDebug}},
+			expected: nil,
+		},
+		{
+			name:     "not sampled",
+			config:   LogConfig{SampleRate: 1e-12},
+			expected: nil,
+		},
+		{
+			name:    "error logged regardless of level",
+			configs: MethodLogConfigs{testMethod: {Level: slog.LevelDebug, SampleRate: 1e-12, ResponseBody: true}},
+			err:     errors.New("failed"),
+			expected: map[string]any{
+				"level":                     "ERROR",
+				elog.RPCRequestBodyContent:  `{"service":"service"}`,
+				elog.RPCResponseBodyContent: nil,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var logWriter bytes.Buffer
+			ctx := econtext.WithLogger(context.Background(), slog.New(slog.NewJSONHandler(&logWriter, nil)))
+
+			logger := NewLogger(test.config, test.configs)
+			_, err := logger.UnaryServer(ctx, &grpc_health_v1.HealthCheckRequest{Service: "service"},
+				&grpc.UnaryServerInfo{FullMethod: testMethod},
+				func(context.Context, any) (any, error) {
+					return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING}, test.err
+				})
+			require.ErrorIs(t, err, test.err)
+
+			if test.expected == nil {
+				require.Empty(t, logWriter.String())
+				return
+			}
+
+			var record map[string]any
+			require.NoError(t, json.Unmarshal(logWriter.Bytes(), &record))
+			for key, value := range test.expected {
+				require.Equal(t, value, record[key], key)
+			}
+		})
+	}
+}
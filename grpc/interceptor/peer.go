@@ -0,0 +1,57 @@
+package interceptor
+
+import (
+	"context"
+	"crypto/x509"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+
+	"github.com/stepanbukhtii/easy-tools/econtext"
+)
+
+const spiffeScheme = "spiffe"
+
+// PeerSubject sets client info subject to the identity of verified peer certificate
+func PeerSubject(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	return handler(setPeerSubject(ctx), req)
+}
+
+// StreamPeerSubject sets client info subject of the stream to the identity of verified peer certificate
+func StreamPeerSubject(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return handler(srv, wrapServerStream(ss, setPeerSubject(ss.Context())))
+}
+
+// PeerIdentity returns SPIFFE ID of verified peer certificate or its common name when
+// certificate has no SPIFFE ID, empty for connections without mTLS.
+func PeerIdentity(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return ""
+	}
+
+	return certificateIdentity(tlsInfo.State.VerifiedChains[0][0])
+}
+
+func certificateIdentity(cert *x509.Certificate) string {
+	for _, uri := range cert.URIs {
+		if uri.Scheme == spiffeScheme {
+			return uri.String()
+		}
+	}
+
+	return cert.Subject.CommonName
+}
+
+func setPeerSubject(ctx context.Context) context.Context {
+	if identity := PeerIdentity(ctx); identity != "" {
+		return econtext.SetSubject(ctx, identity)
+	}
+	return ctx
+}
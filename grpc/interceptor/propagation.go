@@ -0,0 +1,254 @@
+package interceptor
+
+import (
+	"context"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/stepanbukhtii/easy-tools/econtext"
+	"github.com/stepanbukhtii/easy-tools/rest/client"
+)
+
+// Metadata keys of propagated client info and log fields.
+const (
+	MetadataLocale    = "x-client-locale"
+	MetadataIPAddress = "x-client-ip"
+	MetadataUserAgent = "x-client-user-agent"
+	MetadataDeviceID  = "x-client-device-id"
+	MetadataLogFields = "x-log-fields"
+)
+
+// DefaultClientInfoKeys are all client info metadata keys.
+var DefaultClientInfoKeys = []string{MetadataLocale, MetadataIPAddress, MetadataUserAgent, MetadataDeviceID}
+
+type PropagationOption func(p *Propagation)
+
+// WithClientInfoKeys replaces DefaultClientInfoKeys.
+func WithClientInfoKeys(keys ...string) PropagationOption {
+	return func(p *Propagation) { p.clientInfoKeys = keys }
+}
+
+// WithIncomingClientInfo restores client info from metadata of incoming calls when trusted
+// returns true, e.g. TrustVerifiedPeer for internal mTLS hops. Without it incoming client
+// info is ignored, so external callers cannot spoof IP address or device ID.
+func WithIncomingClientInfo(trusted func(ctx context.Context) bool) PropagationOption {
+	return func(p *Propagation) { p.trustedIncoming = trusted }
+}
+
+// TrustVerifiedPeer trusts callers with client certificate verified by mTLS.
+func TrustVerifiedPeer(ctx context.Context) bool {
+	return PeerIdentity(ctx) != ""
+}
+
+// WithLogFields allows econtext.SetLogField keys to be propagated.
+func WithLogFields(keys ...string) PropagationOption {
+	return func(p *Propagation) {
+		for _, key := range keys {
+			p.logFields[key] = struct{}{}
+		}
+	}
+}
+
+// WithBaggage adds fields to log fields of every outgoing call, e.g. the caller service
+// name, the keys are allowed to be propagated further.
+func WithBaggage(fields map[string]string) PropagationOption {
+	return func(p *Propagation) {
+		for key, value := range fields {
+			p.baggage[key] = value
+			p.logFields[key] = struct{}{}
+		}
+	}
+}
+
+// Propagation carries econtext.ClientInfo and allowed log fields over gRPC metadata. Log
+// fields are sent baggage-style in one header as url escaped key=value pairs separated
+// by comma. Both sides filter log fields by the allowlist, servers restore client info
+// only from trusted callers.
+type Propagation struct {
+	clientInfoKeys  []string
+	logFields       map[string]struct{}
+	baggage         map[string]string
+	trustedIncoming func(ctx context.Context) bool
+}
+
+func NewPropagation(opts ...PropagationOption) *Propagation {
+	p := &Propagation{
+		clientInfoKeys: DefaultClientInfoKeys,
+		logFields:      make(map[string]struct{}),
+		baggage:        make(map[string]string),
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+func (p *Propagation) UnaryClient(
+	ctx context.Context,
+	method string,
+	req, reply any,
+	cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker,
+	opts ...grpc.CallOption,
+) error {
+	return invoker(p.outgoing(ctx), method, req, reply, cc, opts...)
+}
+
+func (p *Propagation) StreamClient(
+	ctx context.Context,
+	desc *grpc.StreamDesc,
+	cc *grpc.ClientConn,
+	method string,
+	streamer grpc.Streamer,
+	opts ...grpc.CallOption,
+) (grpc.ClientStream, error) {
+	return streamer(p.outgoing(ctx), desc, cc, method, opts...)
+}
+
+func (p *Propagation) UnaryServer(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	return handler(p.incoming(ctx), req)
+}
+
+func (p *Propagation) StreamServer(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return handler(srv, wrapServerStream(ss, p.incoming(ss.Context())))
+}
+
+func (p *Propagation) outgoing(ctx context.Context) context.Context {
+	pairs := p.pairs(ctx)
+	if len(pairs) == 0 {
+		return ctx
+	}
+
+	return metadata.AppendToOutgoingContext(ctx, pairs...)
+}
+
+// Metadata returns client info and allowed log fields of ctx as metadata, e.g. for
+// incoming context of in-process gateway handlers.
+func (p *Propagation) Metadata(ctx context.Context) metadata.MD {
+	return metadata.Pairs(p.pairs(ctx)...)
+}
+
+func (p *Propagation) pairs(ctx context.Context) []string {
+	clientInfo := econtext.ClientInfo(ctx)
+
+	var pairs []string
+	for _, key := range p.clientInfoKeys {
+		if value := clientInfoValue(clientInfo, key); value != "" {
+			pairs = append(pairs, key, value)
+		}
+	}
+
+	fields := maps.Clone(p.baggage)
+	for key, value := range econtext.LogFields(ctx) {
+		if _, ok := p.logFields[key]; ok {
+			fields[key] = value
+		}
+	}
+	if len(fields) > 0 {
+		pairs = append(pairs, MetadataLogFields, encodeFields(fields))
+	}
+
+	return pairs
+}
+
+func (p *Propagation) incoming(ctx context.Context) context.Context {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx
+	}
+
+	if p.trustedIncoming != nil && p.trustedIncoming(ctx) {
+		ctx = p.incomingClientInfo(ctx, md)
+	}
+
+	for _, header := range md.Get(MetadataLogFields) {
+		for key, value := range decodeFields(header) {
+			if _, ok := p.logFields[key]; ok {
+				ctx = econtext.SetLogField(ctx, key, value)
+			}
+		}
+	}
+
+	return ctx
+}
+
+func (p *Propagation) incomingClientInfo(ctx context.Context, md metadata.MD) context.Context {
+	clientInfo := econtext.ClientInfo(ctx)
+	changed := false
+	for _, key := range p.clientInfoKeys {
+		if values := md.Get(key); len(values) > 0 && values[0] != "" {
+			setClientInfoValue(&clientInfo, key, values[0])
+			changed = true
+		}
+	}
+	if changed {
+		return econtext.SetClientInfo(ctx, clientInfo)
+	}
+
+	return ctx
+}
+
+func clientInfoValue(info client.Info, key string) string {
+	switch key {
+	case MetadataLocale:
+		return info.Locale
+	case MetadataIPAddress:
+		return info.IPAddress
+	case MetadataUserAgent:
+		return info.UserAgent
+	case MetadataDeviceID:
+		return info.DeviceID
+	}
+	return ""
+}
+
+func setClientInfoValue(info *client.Info, key, value string) {
+	switch key {
+	case MetadataLocale:
+		info.Locale = value
+	case MetadataIPAddress:
+		info.IPAddress = value
+	case MetadataUserAgent:
+		info.UserAgent = value
+	case MetadataDeviceID:
+		info.DeviceID = value
+	}
+}
+
+func encodeFields(fields map[string]string) string {
+	pairs := make([]string, 0, len(fields))
+	for _, key := range slices.Sorted(maps.Keys(fields)) {
+		pairs = append(pairs, url.QueryEscape(key)+"="+url.QueryEscape(fields[key]))
+	}
+	return strings.Join(pairs, ",")
+}
+
+func decodeFields(header string) map[string]string {
+	fields := make(map[string]string)
+	for pair := range strings.SplitSeq(header, ",") {
+		rawKey, rawValue, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			continue
+		}
+
+		key, err := url.QueryUnescape(rawKey)
+		if err != nil || key == "" {
+			continue
+		}
+
+		value, err := url.QueryUnescape(rawValue)
+		if err != nil {
+			continue
+		}
+
+		fields[key] = value
+	}
+	return fields
+}
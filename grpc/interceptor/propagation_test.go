@@ -0,0 +1,97 @@
+package interceptor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/stepanbukhtii/easy-tools/econtext"
+	"github.com/stepanbukhtii/easy-tools/rest/client"
+)
+
+func TestPropagation(t *testing.T) {
+	clientPropagation := NewPropagation(
+		WithLogFields("order.id", "note"),
+		WithBaggage(map[string]string{"caller.service": "orders"}),
+	)
+	serverPropagation := NewPropagation(
+		WithClientInfoKeys(MetadataLocale, MetadataIPAddress, MetadataDeviceID),
+		WithLogFields("order.id", "caller.service", "note"),
+		WithIncomingClientInfo(func(context.Context) bool { return true }),
+	)
+
+	ctx := econtext.SetClientInfo(context.Background(), client.Info{
+		Subject:   "user",
+		Locale:    "uk",
+		IPAddress: "10.0.0.1",
+		UserAgent: "browser",
+		DeviceID:  "device",
+	})
+	ctx = econtext.SetLogField(ctx, "order.id", "42")
+	ctx = econtext.SetLogField(ctx, "note", "a=b, c")
+	ctx = econtext.SetLogField(ctx, "secret", "token")
+
+	var outgoing metadata.MD
+	err := clientPropagation.UnaryClient(ctx, testMethod, nil, nil, nil,
+		func(ctx context.Context, _ string, _, _ any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+			outgoing, _ = metadata.FromOutgoingContext(ctx)
+			return nil
+		})
+	require.NoError(t, err)
+
+	var serverCtx context.Context
+	_, err = serverPropagation.UnaryServer(metadata.NewIncomingContext(context.Background(), outgoing), nil,
+		&grpc.UnaryServerInfo{FullMethod: testMethod},
+		func(ctx context.Context, _ any) (any, error) {
+			serverCtx = ctx
+			return nil, nil
+		})
+	require.NoError(t, err)
+
+	require.Equal(t, client.Info{
+		Locale:    "uk",
+		IPAddress: "10.0.0.1",
+		DeviceID:  "device",
+	}, econtext.ClientInfo(serverCtx))
+
+	require.Equal(t, map[string]string{
+		"order.id":       "42",
+		"note":           "a=b, c",
+		"caller.service": "orders",
+	}, econtext.LogFields(serverCtx))
+}
+
+func TestPropagationUntrustedClientInfo(t *testing.T) {
+	incoming := metadata.Pairs(MetadataIPAddress, "10.0.0.1", MetadataDeviceID, "device")
+
+	for name, propagation := range map[string]*Propagation{
+		"default":      NewPropagation(),
+		"not verified": NewPropagation(WithIncomingClientInfo(TrustVerifiedPeer)),
+	} {
+		t.Run(name, func(t *testing.T) {
+			var serverCtx context.Context
+			_, err := propagation.UnaryServer(metadata.NewIncomingContext(context.Background(), incoming), nil,
+				&grpc.UnaryServerInfo{FullMethod: testMethod},
+				func(ctx context.Context, _ any) (any, error) {
+					serverCtx = ctx
+					return nil, nil
+				})
+			require.NoError(t, err)
+			require.Equal(t, client.Info{}, econtext.ClientInfo(serverCtx))
+		})
+	}
+}
+
+func TestPropagationWithoutValues(t *testing.T) {
+	var outgoing metadata.MD
+	err := NewPropagation().UnaryClient(context.Background(), testMethod, nil, nil, nil,
+		func(ctx context.Context, _ string, _, _ any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+			outgoing, _ = metadata.FromOutgoingContext(ctx)
+			return nil
+		})
+	require.NoError(t, err)
+	require.Empty(t, outgoing)
+}
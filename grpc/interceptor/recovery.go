@@ -3,6 +3,7 @@ package interceptor
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"runtime/debug"
 
@@ -12,32 +13,41 @@ import (
 	"google.golang.org/grpc/status"
 )
 
-func Recovery(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
-	var resp any
-	var err error
+func Recovery(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
 	defer func() {
 		if r := recover(); r != nil {
-			var recoverErr error
-			switch x := r.(type) {
-			case string:
-				recoverErr = errors.New(x)
-			case error:
-				recoverErr = x
-			}
-
-			slog.With(
-				slog.String(string(semconv.ExceptionStacktraceKey), string(debug.Stack())),
-				slog.Any(string(semconv.ExceptionMessageKey), recoverErr),
-			).Error("panic recovered")
-
-			err = status.Errorf(codes.Internal, "Internal server error")
+			resp, err = nil, recoverError(r)
 		}
 	}()
 
-	resp, err = handler(ctx, req)
-	if err != nil {
-		return nil, err
+	return handler(ctx, req)
+}
+
+func StreamRecovery(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = recoverError(r)
+		}
+	}()
+
+	return handler(srv, ss)
+}
+
+func recoverError(r any) error {
+	var recoverErr error
+	switch x := r.(type) {
+	case string:
+		recoverErr = errors.New(x)
+	case error:
+		recoverErr = x
+	default:
+		recoverErr = fmt.Errorf("%v", x)
 	}
 
-	return resp, nil
+	slog.With(
+		slog.String(string(semconv.ExceptionStacktraceKey), string(debug.Stack())),
+		slog.Any(string(semconv.ExceptionMessageKey), recoverErr),
+	).Error("panic recovered")
+
+	return status.Errorf(codes.Internal, "Internal server error")
 }
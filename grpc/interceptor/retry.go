@@ -0,0 +1,250 @@
+package interceptor
+
+import (
+	"context"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// DefaultRetryPolicy retries unavailable and resource exhausted calls, which are not
+// processed by the server, so it is safe for methods that are not idempotent.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries:        3,
+	RetryableCodes:    []codes.Code{codes.Unavailable, codes.ResourceExhausted},
+	InitialBackoff:    100 * time.Millisecond,
+	MaxBackoff:        5 * time.Second,
+	BackoffMultiplier: 2,
+	Jitter:            0.2,
+	PerAttemptTimeout: 30 * time.Second,
+}
+
+// RetryPolicy describes retries of a method. With HedgingDelay set the policy hedges:
+// a new attempt is started every HedgingDelay, or right after a retryable failure,
+// without waiting for the previous ones and the first final result wins. Hedge only
+// idempotent reads, the server may process every attempt.
+type RetryPolicy struct {
+	MaxRetries        int
+	RetryableCodes    []codes.Code
+	InitialBackoff    time.Duration
+	MaxBackoff        time.Duration
+	BackoffMultiplier float64
+	// Jitter randomizes backoff by the fraction in both directions
+	Jitter            float64
+	PerAttemptTimeout time.Duration
+	HedgingDelay      time.Duration
+}
+
+func (p RetryPolicy) retryable(err error) bool {
+	return slices.Contains(p.RetryableCodes, status.Code(err))
+}
+
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	backoff := float64(p.InitialBackoff)
+	for range retry {
+		backoff *= max(p.BackoffMultiplier, 1)
+	}
+
+	if p.MaxBackoff > 0 {
+		backoff = min(backoff, float64(p.MaxBackoff))
+	}
+
+	if p.Jitter > 0 {
+		backoff *= 1 + p.Jitter*(2*rand.Float64()-1)
+	}
+
+	return time.Duration(backoff)
+}
+
+// MethodRetryPolicies maps full method, or service prefix ending with "/", to retry policy
+type MethodRetryPolicies map[string]RetryPolicy
+
+// RetryBudget limits retries when most calls fail, like gRPC retry throttling: every
+// failure with a retryable code takes a token, every success returns tokenRatio, other
+// failures do not change the budget, retries are allowed only
+// while more than half of maxTokens is left.
+type RetryBudget struct {
+	mu         sync.Mutex
+	tokens     float64
+	maxTokens  float64
+	tokenRatio float64
+}
+
+func NewRetryBudget(maxTokens, tokenRatio float64) *RetryBudget {
+	return &RetryBudget{
+		tokens:     maxTokens,
+		maxTokens:  maxTokens,
+		tokenRatio: tokenRatio,
+	}
+}
+
+func (b *RetryBudget) allow() bool {
+	if b == nil {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.tokens > b.maxTokens/2
+}
+
+func (b *RetryBudget) record(policy RetryPolicy, err error) {
+	if b == nil || (err != nil && !policy.retryable(err)) {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err != nil {
+		b.tokens = max(b.tokens-1, 0)
+		return
+	}
+	b.tokens = min(b.tokens+b.tokenRatio, b.maxTokens)
+}
+
+type Retry struct {
+	defaultPolicy RetryPolicy
+	policies      MethodRetryPolicies
+	budget        *RetryBudget
+}
+
+// NewRetry creates retry interceptor, methods missing in policies use defaultPolicy,
+// nil budget does not limit retries.
+func NewRetry(defaultPolicy RetryPolicy, policies MethodRetryPolicies, budget *RetryBudget) *Retry {
+	return &Retry{
+		defaultPolicy: defaultPolicy,
+		policies:      policies,
+		budget:        budget,
+	}
+}
+
+func (r *Retry) Unary(
+	ctx context.Context,
+	method string,
+	req, reply any,
+	cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker,
+	opts ...grpc.CallOption,
+) error {
+	policy := r.policy(method)
+
+	if _, ok := reply.(proto.Message); ok && policy.HedgingDelay > 0 {
+		return r.hedge(ctx, policy, method, req, reply.(proto.Message), cc, invoker, opts...)
+	}
+
+	for retry := 0; ; retry++ {
+		err := r.attempt(ctx, policy, method, req, reply, cc, invoker, opts...)
+		if err == nil || !policy.retryable(err) || retry >= policy.MaxRetries || !r.budget.allow() {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-time.After(policy.backoff(retry)):
+		}
+	}
+}
+
+func (r *Retry) hedge(
+	ctx context.Context,
+	policy RetryPolicy,
+	method string,
+	req any,
+	reply proto.Message,
+	cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker,
+	opts ...grpc.CallOption,
+) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		reply proto.Message
+		err   error
+	}
+
+	results := make(chan result, policy.MaxRetries+1)
+	start := func() {
+		attemptReply := proto.Clone(reply)
+		go func() {
+			err := r.attempt(ctx, policy, method, req, attemptReply, cc, invoker, opts...)
+			results <- result{reply: attemptReply, err: err}
+		}()
+	}
+
+	start()
+	started, running := 1, 1
+
+	var lastErr error
+	for running > 0 {
+		var hedgeTimer <-chan time.Time
+		if started <= policy.MaxRetries && r.budget.allow() {
+			hedgeTimer = time.After(policy.HedgingDelay)
+		}
+
+		select {
+		case res := <-results:
+			running--
+			if res.err == nil || !policy.retryable(res.err) {
+				if res.err == nil {
+					proto.Reset(reply)
+					proto.Merge(reply, res.reply)
+				}
+				return res.err
+			}
+			lastErr = res.err
+
+			if started <= policy.MaxRetries && r.budget.allow() {
+				start()
+				started++
+				running++
+			}
+		case <-hedgeTimer:
+			start()
+			started++
+			running++
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		}
+	}
+
+	return lastErr
+}
+
+func (r *Retry) attempt(
+	ctx context.Context,
+	policy RetryPolicy,
+	method string,
+	req, reply any,
+	cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker,
+	opts ...grpc.CallOption,
+) error {
+	if policy.PerAttemptTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, policy.PerAttemptTimeout)
+		defer cancel()
+	}
+
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	r.budget.record(policy, err)
+
+	return err
+}
+
+func (r *Retry) policy(method string) RetryPolicy {
+	if policy, ok := matchMethod(r.policies, method); ok {
+		return policy
+	}
+
+	return r.defaultPolicy
+}
@@ -0,0 +1,205 @@
+package interceptor
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/embedded"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+func TestRetry(t *testing.T) {
+	policy := RetryPolicy{
+		MaxRetries:     3,
+		RetryableCodes: []codes.Code{codes.Unavailable},
+		InitialBackoff: time.Millisecond,
+		Jitter:         0.5,
+	}
+
+	tests := []struct {
+		name             string
+		policies         MethodRetryPolicies
+		budget           *RetryBudget
+		failures         int
+		failureCode      codes.Code
+		expectedCode     codes.Code
+		expectedAttempts int32
+	}{
+		{
+			name:             "success after retries",
+			failures:         2,
+			failureCode:      codes.Unavailable,
+			expectedAttempts: 3,
+		}, {
+			name:             "max retries",
+			failures:         10,
+			failureCode:      codes.Unavailable,
+			expectedCode:     codes.Unavailable,
+			expectedAttempts: 4,
+		}, {
+			name:             "not retryable",
+			failures:         1,
+			failureCode:      codes.InvalidArgument,
+			expectedCode:     codes.InvalidArgument,
+			expectedAttempts: 1,
+		}, {
+			name:             "service policy",
+			policies:         MethodRetryPolicies{"/test.v1.Service/": {}},
+			failures:         1,
+			failureCode:      codes.Unavailable,
+			expectedCode:     codes.Unavailable,
+			expectedAttempts: 1,
+		}, {
+			name:             "budget",
+			budget:           NewRetryBudget(4, 0.1),
+			failures:         10,
+			failureCode:      codes.Unavailable,
+			expectedCode:     codes.Unavailable,
+			expectedAttempts: 2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			invoker := func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+				if int(attempts.Add(1)) <= test.failures {
+					return status.Error(test.failureCode, "failure")
+				}
+				return nil
+			}
+
+			retry := NewRetry(policy, test.policies, test.budget)
+			err := retry.Unary(context.Background(), testMethod, nil, nil, nil, invoker)
+
+			require.Equal(t, test.expectedCode, status.Code(err))
+			require.Equal(t, test.expectedAttempts, attempts.Load())
+		})
+	}
+}
+
+func TestRetryBudget(t *testing.T) {
+	policy := RetryPolicy{RetryableCodes: []codes.Code{codes.Unavailable}}
+	budget := NewRetryBudget(4, 0.1)
+
+	for range 10 {
+		budget.record(policy, status.Error(codes.NotFound, "not found"))
+	}
+	require.True(t, budget.allow())
+
+	budget.record(policy, status.Error(codes.Unavailable, "unavailable"))
+	budget.record(policy, status.Error(codes.Unavailable, "unavailable"))
+	require.False(t, budget.allow())
+
+	budget.record(policy, nil)
+	require.True(t, budget.allow())
+}
+
+func TestRetryHedging(t *testing.T) {
+	policy := RetryPolicy{
+		MaxRetries:     2,
+		RetryableCodes: []codes.Code{codes.Unavailable},
+		HedgingDelay:   10 * time.Millisecond,
+	}
+
+	var attempts atomic.Int32
+	invoker := func(ctx context.Context, _ string, _, reply any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		if attempts.Add(1) == 1 {
+			// first attempt hangs until the hedged one wins
+			<-ctx.Done()
+			return status.FromContextError(ctx.Err()).Err()
+		}
+		reply.(*grpc_health_v1.HealthCheckResponse).Status = grpc_health_v1.HealthCheckResponse_SERVING
+		return nil
+	}
+
+	reply := &grpc_health_v1.HealthCheckResponse{}
+	err := NewRetry(policy, nil, nil).Unary(context.Background(), testMethod, nil, reply, nil, invoker)
+	require.NoError(t, err)
+	require.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, reply.Status)
+	require.Equal(t, int32(2), attempts.Load())
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	circuitBreaker, err := NewCircuitBreaker("test", CircuitBreakerConfig{
+		FailureThreshold: 2,
+		OpenTimeout:      20 * time.Millisecond,
+		HalfOpenMaxCalls: 1,
+		FailureCodes:     []codes.Code{codes.Unavailable},
+	})
+	require.NoError(t, err)
+
+	var calls int
+	call := func(code codes.Code) error {
+		return circuitBreaker.Unary(context.Background(), testMethod, nil, nil, nil,
+			func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+				calls++
+				return status.Error(code, "")
+			})
+	}
+
+	require.Equal(t, codes.NotFound, status.Code(call(codes.NotFound)))
+	require.Equal(t, codes.Unavailable, status.Code(call(codes.Unavailable)))
+	require.Equal(t, CircuitClosed, circuitBreaker.State())
+	require.Equal(t, codes.Unavailable, status.Code(call(codes.Unavailable)))
+	require.Equal(t, CircuitOpen, circuitBreaker.State())
+
+	// rejected without calling the server
+	require.Equal(t, codes.Unavailable, status.Code(call(codes.OK)))
+	require.Equal(t, 3, calls)
+
+	time.Sleep(25 * time.Millisecond)
+	require.Equal(t, CircuitHalfOpen, circuitBreaker.State())
+
+	// failed probe opens the circuit again
+	require.Equal(t, codes.Unavailable, status.Code(call(codes.Unavailable)))
+	require.Equal(t, CircuitOpen, circuitBreaker.State())
+
+	time.Sleep(25 * time.Millisecond)
+	require.NoError(t, call(codes.OK))
+	require.Equal(t, CircuitClosed, circuitBreaker.State())
+	require.Equal(t, 5, calls)
+}
+
+type testInt64Observer struct {
+	embedded.Int64Observer
+	values map[string]int64
+}
+
+func (o *testInt64Observer) Observe(value int64, options ...metric.ObserveOption) {
+	attrs := metric.NewObserveConfig(options).Attributes()
+	name, _ := attrs.Value("circuit_breaker.name")
+	o.values[name.AsString()] = value
+}
+
+func TestCircuitBreakerStateMetric(t *testing.T) {
+	config := CircuitBreakerConfig{FailureThreshold: 1, OpenTimeout: time.Minute, FailureCodes: []codes.Code{codes.Unavailable}}
+
+	first, err := NewCircuitBreaker("metric-test", config)
+	require.NoError(t, err)
+	second, err := NewCircuitBreaker("metric-test", config)
+	require.NoError(t, err)
+
+	_ = second.Unary(context.Background(), testMethod, nil, nil, nil,
+		func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+			return status.Error(codes.Unavailable, "")
+		})
+
+	observer := &testInt64Observer{values: make(map[string]int64)}
+	require.NoError(t, observeCircuitBreakers(context.Background(), observer))
+	require.Equal(t, int64(CircuitOpen), observer.values["metric-test"])
+
+	first.Close()
+	second.Close()
+
+	observer = &testInt64Observer{values: make(map[string]int64)}
+	require.NoError(t, observeCircuitBreakers(context.Background(), observer))
+	require.NotContains(t, observer.values, "metric-test")
+}
@@ -0,0 +1,117 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// serverStream overrides the context of the wrapped grpc.ServerStream
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func wrapServerStream(ss grpc.ServerStream, ctx context.Context) grpc.ServerStream {
+	return &serverStream{ServerStream: ss, ctx: ctx}
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// streamStats counts messages and their protobuf size in both directions
+type streamStats struct {
+	messagesSent     atomic.Int64
+	messagesReceived atomic.Int64
+	bytesSent        atomic.Int64
+	bytesReceived    atomic.Int64
+}
+
+func (s *streamStats) sent(m any) {
+	s.messagesSent.Add(1)
+	s.bytesSent.Add(int64(messageSize(m)))
+}
+
+func (s *streamStats) received(m any) {
+	s.messagesReceived.Add(1)
+	s.bytesReceived.Add(int64(messageSize(m)))
+}
+
+func messageSize(m any) int {
+	if message, ok := m.(proto.Message); ok {
+		return proto.Size(message)
+	}
+	return 0
+}
+
+type statsServerStream struct {
+	grpc.ServerStream
+	stats *streamStats
+}
+
+func (s *statsServerStream) SendMsg(m any) error {
+	if err := s.ServerStream.SendMsg(m); err != nil {
+		return err
+	}
+	s.stats.sent(m)
+	return nil
+}
+
+func (s *statsServerStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	s.stats.received(m)
+	return nil
+}
+
+// statsClientStream calls finish once when the stream is over: on receive error, io.EOF
+// included, or after the single response of a stream without server streaming.
+type statsClientStream struct {
+	grpc.ClientStream
+	stats         *streamStats
+	serverStreams bool
+	finishOnce    sync.Once
+	finish        func(err error)
+}
+
+func (s *statsClientStream) SendMsg(m any) error {
+	err := s.ClientStream.SendMsg(m)
+	if err != nil {
+		if !errors.Is(err, io.EOF) {
+			s.done(err)
+		}
+		return err
+	}
+	s.stats.sent(m)
+	return nil
+}
+
+func (s *statsClientStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	switch {
+	case errors.Is(err, io.EOF):
+		s.done(nil)
+		return err
+	case err != nil:
+		s.done(err)
+		return err
+	}
+
+	s.stats.received(m)
+	if !s.serverStreams {
+		s.done(nil)
+	}
+
+	return nil
+}
+
+func (s *statsClientStream) done(err error) {
+	s.finishOnce.Do(func() { s.finish(err) })
+}
@@ -0,0 +1,137 @@
+package interceptor
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+type testMessageStream struct {
+	testServerStream
+	received int
+}
+
+func (s *testMessageStream) SendMsg(any) error {
+	return nil
+}
+
+func (s *testMessageStream) RecvMsg(m any) error {
+	if s.received == 2 {
+		return io.EOF
+	}
+	s.received++
+	m.(*grpc_health_v1.HealthCheckRequest).Service = "service"
+	return nil
+}
+
+type testClientStream struct {
+	grpc.ClientStream
+	responses int
+}
+
+func (s *testClientStream) SendMsg(any) error {
+	return nil
+}
+
+func (s *testClientStream) RecvMsg(any) error {
+	if s.responses == 0 {
+		return io.EOF
+	}
+	s.responses--
+	return nil
+}
+
+func TestStreamRecovery(t *testing.T) {
+	err := StreamRecovery(nil, testServerStream{ctx: context.Background()}, &grpc.StreamServerInfo{FullMethod: testMethod},
+		func(any, grpc.ServerStream) error {
+			panic("stream panic")
+		})
+	require.Equal(t, codes.Internal, status.Code(err))
+
+	_, err = Recovery(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: testMethod},
+		func(context.Context, any) (any, error) {
+			panic(42)
+		})
+	require.Equal(t, codes.Internal, status.Code(err))
+}
+
+func TestStreamServerLogger(t *testing.T) {
+	stream := &testMessageStream{testServerStream: testServerStream{ctx: context.Background()}}
+
+	var stats *streamStats
+	err := StreamServerLogger(nil, stream, &grpc.StreamServerInfo{FullMethod: testMethod},
+		func(_ any, ss grpc.ServerStream) error {
+			stats = ss.(*statsServerStream).stats
+
+			for {
+				req := &grpc_health_v1.HealthCheckRequest{}
+				if err := ss.RecvMsg(req); err != nil {
+					break
+				}
+				if err := ss.SendMsg(&grpc_health_v1.HealthCheckResponse{
+					Status: grpc_health_v1.HealthCheckResponse_SERVING,
+				}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	require.NoError(t, err)
+
+	requestSize := proto.Size(&grpc_health_v1.HealthCheckRequest{Service: "service"})
+	responseSize := proto.Size(&grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING})
+
+	require.Equal(t, int64(2), stats.messagesReceived.Load())
+	require.Equal(t, int64(2), stats.messagesSent.Load())
+	require.Equal(t, int64(2*requestSize), stats.bytesReceived.Load())
+	require.Equal(t, int64(2*responseSize), stats.bytesSent.Load())
+}
+
+func TestStreamClientLogger(t *testing.T) {
+	tests := []struct {
+		name          string
+		serverStreams bool
+		responses     int
+		expectedRecv  int64
+	}{
+		{name: "server streaming", serverStreams: true, responses: 3, expectedRecv: 3},
+		{name: "client streaming", responses: 1, expectedRecv: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cs, err := StreamClientLogger(context.Background(), &grpc.StreamDesc{ServerStreams: test.serverStreams}, nil,
+				testMethod, func(context.Context, *grpc.StreamDesc, *grpc.ClientConn, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+					return &testClientStream{responses: test.responses}, nil
+				})
+			require.NoError(t, err)
+
+			stream := cs.(*statsClientStream)
+			var finished int
+			finish := stream.finish
+			stream.finish = func(err error) {
+				finished++
+				finish(err)
+			}
+
+			require.NoError(t, cs.SendMsg(&grpc_health_v1.HealthCheckRequest{}))
+			for range test.responses {
+				require.NoError(t, cs.RecvMsg(&grpc_health_v1.HealthCheckResponse{}))
+			}
+			if test.serverStreams {
+				require.ErrorIs(t, cs.RecvMsg(&grpc_health_v1.HealthCheckResponse{}), io.EOF)
+			}
+
+			require.Equal(t, 1, finished)
+			require.Equal(t, int64(1), stream.stats.messagesSent.Load())
+			require.Equal(t, test.expectedRecv, stream.stats.messagesReceived.Load())
+		})
+	}
+}
@@ -0,0 +1,286 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stepanbukhtii/easy-tools/elog"
+	semconv "go.opentelemetry.io/otel/semconv/v1.40.0"
+	"google.golang.org/grpc/resolver"
+)
+
+// Resolver schemes of NewDNSResolver and NewStaticResolver targets.
+const (
+	SchemeDNS    = "dns"
+	SchemeStatic = "static"
+)
+
+var (
+	// DefaultResolveInterval is the re-resolution period of NewDNSResolver.
+	DefaultResolveInterval = 30 * time.Second
+	// DefaultMinResolveInterval limits lookups requested by the connection, it asks for
+	// re-resolution on every sub connection failure.
+	DefaultMinResolveInterval = 30 * time.Second
+	// DefaultDNSPort is used by DNSRegistry for targets without port, like the built-in resolver.
+	DefaultDNSPort = "443"
+)
+
+var ErrNoInstances = errors.New("no service instances")
+
+// Instance is an address of service replica, Weight is used by weighted least-request
+// balancer, zero weight is treated as 1.
+type Instance struct {
+	Address string
+	Weight  uint32
+}
+
+// Registry discovers service instances, e.g. from Consul or Kubernetes endpoints. Service
+// is the target endpoint, "orders:50051" for "dns:///orders:50051".
+type Registry interface {
+	Lookup(ctx context.Context, service string) ([]Instance, error)
+}
+
+type RegistryFunc func(ctx context.Context, service string) ([]Instance, error)
+
+func (f RegistryFunc) Lookup(ctx context.Context, service string) ([]Instance, error) {
+	return f(ctx, service)
+}
+
+// StaticRegistry returns the same instances for every service.
+func StaticRegistry(instances ...Instance) Registry {
+	return RegistryFunc(func(context.Context, string) ([]Instance, error) {
+		return instances, nil
+	})
+}
+
+// DNSRegistry resolves "host:port" service to addresses of the host, nil resolver uses
+// net.DefaultResolver.
+func DNSRegistry(dnsResolver *net.Resolver) Registry {
+	if dnsResolver == nil {
+		dnsResolver = net.DefaultResolver
+	}
+
+	return RegistryFunc(func(ctx context.Context, service string) ([]Instance, error) {
+		host, port, err := net.SplitHostPort(service)
+		var addrErr *net.AddrError
+		if errors.As(err, &addrErr) && addrErr.Err == "missing port in address" {
+			host, port, err = service, DefaultDNSPort, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		hosts, err := dnsResolver.LookupHost(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+
+		instances := make([]Instance, len(hosts))
+		for i, h := range hosts {
+			instances[i] = Instance{Address: net.JoinHostPort(h, port)}
+		}
+		return instances, nil
+	})
+}
+
+type ResolverOption func(b *registryResolverBuilder)
+
+// WithMinResolveInterval replaces DefaultMinResolveInterval.
+func WithMinResolveInterval(interval time.Duration) ResolverOption {
+	return func(b *registryResolverBuilder) { b.minInterval = interval }
+}
+
+// NewResolver creates resolver builder of scheme which looks up registry every interval and
+// when the connection asks for re-resolution, but not more often than min resolve interval.
+// Zero interval disables the periodic lookup. Pass it with grpc.WithResolvers or
+// ClientConnectionParams.Resolver.
+func NewResolver(scheme string, registry Registry, interval time.Duration, opts ...ResolverOption) resolver.Builder {
+	b := &registryResolverBuilder{
+		scheme:      scheme,
+		registry:    registry,
+		interval:    interval,
+		minInterval: DefaultMinResolveInterval,
+	}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	return b
+}
+
+// NewDNSResolver replaces the built-in "dns" resolver of the connection with the one that
+// also re-resolves every interval, so new replicas are picked up without connection errors.
+func NewDNSResolver(interval time.Duration, opts ...ResolverOption) resolver.Builder {
+	return NewResolver(SchemeDNS, DNSRegistry(nil), interval, opts...)
+}
+
+// NewStaticResolver resolves "static:///name" targets to the addresses.
+func NewStaticResolver(addresses ...string) resolver.Builder {
+	instances := make([]Instance, len(addresses))
+	for i, address := range addresses {
+		instances[i] = Instance{Address: address}
+	}
+
+	return NewResolver(SchemeStatic, StaticRegistry(instances...), 0)
+}
+
+type registryResolverBuilder struct {
+	scheme      string
+	registry    Registry
+	interval    time.Duration
+	minInterval time.Duration
+}
+
+func (b *registryResolverBuilder) Build(
+	target resolver.Target,
+	cc resolver.ClientConn,
+	_ resolver.BuildOptions,
+) (resolver.Resolver, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	r := &registryResolver{
+		registry:    b.registry,
+		service:     target.Endpoint(),
+		interval:    b.interval,
+		minInterval: b.minInterval,
+		cc:          cc,
+		cancel:      cancel,
+		resolveNow:  make(chan struct{}, 1),
+	}
+
+	r.wg.Add(1)
+	go r.watch(ctx)
+
+	return r, nil
+}
+
+func (b *registryResolverBuilder) Scheme() string {
+	return b.scheme
+}
+
+type registryResolver struct {
+	registry    Registry
+	service     string
+	interval    time.Duration
+	minInterval time.Duration
+	cc          resolver.ClientConn
+	cancel      context.CancelFunc
+	resolveNow  chan struct{}
+	wg          sync.WaitGroup
+
+	addresses []string
+}
+
+func (r *registryResolver) ResolveNow(resolver.ResolveNowOptions) {
+	select {
+	case r.resolveNow <- struct{}{}:
+	default:
+	}
+}
+
+func (r *registryResolver) Close() {
+	r.cancel()
+	r.wg.Wait()
+}
+
+func (r *registryResolver) watch(ctx context.Context) {
+	defer r.wg.Done()
+
+	var tick <-chan time.Time
+	if r.interval > 0 {
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		lastResolve := time.Now()
+		r.resolve(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick:
+		case <-r.resolveNow:
+			if !r.waitMinInterval(ctx, lastResolve) {
+				return
+			}
+		}
+	}
+}
+
+// waitMinInterval delays requested re-resolution until min interval since the last lookup
+// has passed, returns false when the resolver is closed.
+func (r *registryResolver) waitMinInterval(ctx context.Context, lastResolve time.Time) bool {
+	wait := r.minInterval - time.Since(lastResolve)
+	if wait <= 0 {
+		return true
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+	}
+
+	// requests received while waiting are served by the delayed lookup
+	select {
+	case <-r.resolveNow:
+	default:
+	}
+
+	return true
+}
+
+func (r *registryResolver) resolve(ctx context.Context) {
+	instances, err := r.registry.Lookup(ctx, r.service)
+	if err == nil && len(instances) == 0 {
+		err = ErrNoInstances
+	}
+	if err != nil {
+		if ctx.Err() == nil {
+			r.cc.ReportError(fmt.Errorf("lookup %s: %w", r.service, err))
+		}
+		return
+	}
+
+	addresses := make([]resolver.Address, len(instances))
+	for i, instance := range instances {
+		addresses[i] = SetAddressWeight(resolver.Address{Addr: instance.Address}, instance.Weight)
+	}
+
+	if err := r.cc.UpdateState(resolver.State{Addresses: addresses}); err != nil {
+		slog.With(slog.String(string(semconv.ServerAddressKey), r.service), elog.Err(err)).Warn("gRPC resolver state rejected")
+	}
+
+	r.logChanges(instances)
+}
+
+func (r *registryResolver) logChanges(instances []Instance) {
+	addresses := make([]string, len(instances))
+	for i, instance := range instances {
+		addresses[i] = instance.Address
+	}
+	slices.Sort(addresses)
+
+	if slices.Equal(addresses, r.addresses) {
+		return
+	}
+	r.addresses = addresses
+
+	slog.With(
+		slog.String(string(semconv.ServerAddressKey), r.service),
+		slog.String("addresses", strings.Join(addresses, ",")),
+	).Info("gRPC resolver addresses updated")
+}
@@ -1,33 +1,174 @@
 package grpc
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net"
+	"slices"
+	"time"
 
 	"github.com/stepanbukhtii/easy-tools/config"
 	"github.com/stepanbukhtii/easy-tools/grpc/interceptor"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/reflection"
 )
 
 var (
 	DefaultServerInterceptors = []grpc.UnaryServerInterceptor{
 		interceptor.Recovery,
+		interceptor.ServerErrors,
 		interceptor.ServerLogger,
 	}
+	DefaultServerStreamInterceptors = []grpc.StreamServerInterceptor{
+		interceptor.StreamRecovery,
+		interceptor.StreamServerErrors,
+		interceptor.StreamServerLogger,
+	}
+
+	// DefaultPropagation sends client info of outgoing calls and restores allowed log fields of
+	// incoming calls, incoming client info is restored only with interceptor.WithIncomingClientInfo.
+	DefaultPropagation = interceptor.NewPropagation()
+
+	// DefaultShutdownTimeout is used by Run when config.GRPC.ShutdownTimeout is not set.
+	DefaultShutdownTimeout = 30 * time.Second
 )
 
+// Server registers grpc.health.v1 service when config.GRPC.HealthEnabled is set, overall
+// health is the "" service. Leave it disabled to register own health server, SetServing and
+// SetNotServing only log a warning then.
 type Server struct {
 	*grpc.Server
+	health *health.Server
+	config config.GRPC
 }
 
 func NewServer(interceptors ...grpc.UnaryServerInterceptor) Server {
-	return Server{
-		Server: grpc.NewServer(
-			grpc.ChainUnaryInterceptor(append(DefaultServerInterceptors, interceptors...)...),
-			grpc.StatsHandler(otelgrpc.NewServerHandler()),
-		),
+	return NewServerParams(ServerParams{UnaryInterceptors: interceptors})
+}
+
+// NewServerConfig creates server with TLS or mTLS credentials, keepalive, message size and
+// connection age options from config, plaintext is used when TLS is disabled.
+func NewServerConfig(cfg config.GRPC, interceptors ...grpc.UnaryServerInterceptor) (Server, error) {
+	creds, err := ServerCredentials(cfg)
+	if err != nil {
+		return Server{}, err
+	}
+
+	return NewServerParams(ServerParams{
+		Config:            cfg,
+		UnaryInterceptors: interceptors,
+		ServerOptions:     append(ServerOptions(cfg), grpc.Creds(creds)),
+	}), nil
+}
+
+type ServerParams struct {
+	Config             config.GRPC
+	Propagation        *interceptor.Propagation
+	UnaryInterceptors  []grpc.UnaryServerInterceptor
+	StreamInterceptors []grpc.StreamServerInterceptor
+	ServerOptions      []grpc.ServerOption
+}
+
+// NewServerParams creates server with interceptors appended to the default ones, metadata
+// propagation runs first so the default logger has the caller client info and log fields.
+func NewServerParams(params ServerParams) Server {
+	propagation := params.Propagation
+	if propagation == nil {
+		propagation = DefaultPropagation
+	}
+
+	unaryInterceptors := slices.Concat(
+		[]grpc.UnaryServerInterceptor{propagation.UnaryServer},
+		DefaultServerInterceptors,
+		params.UnaryInterceptors,
+	)
+	streamInterceptors := slices.Concat(
+		[]grpc.StreamServerInterceptor{propagation.StreamServer},
+		DefaultServerStreamInterceptors,
+		params.StreamInterceptors,
+	)
+
+	opts := slices.Concat(params.ServerOptions, []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unaryInterceptors...),
+		grpc.ChainStreamInterceptor(streamInterceptors...),
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+	})
+
+	s := Server{
+		Server: grpc.NewServer(opts...),
+		health: health.NewServer(),
+		config: params.Config,
+	}
+
+	if params.Config.HealthEnabled {
+		grpc_health_v1.RegisterHealthServer(s.Server, s.health)
+	}
+
+	if params.Config.ReflectionEnabled {
+		reflection.Register(s.Server)
+	}
+
+	return s
+}
+
+// ServerOptions returns keepalive, message size and connection age options set in config.
+func ServerOptions(cfg config.GRPC) []grpc.ServerOption {
+	var opts []grpc.ServerOption
+
+	if cfg.MaxRecvMsgSize > 0 {
+		opts = append(opts, grpc.MaxRecvMsgSize(cfg.MaxRecvMsgSize))
+	}
+
+	if cfg.MaxSendMsgSize > 0 {
+		opts = append(opts, grpc.MaxSendMsgSize(cfg.MaxSendMsgSize))
+	}
+
+	params := keepalive.ServerParameters{
+		MaxConnectionIdle:     cfg.MaxConnectionIdle,
+		MaxConnectionAge:      cfg.MaxConnectionAge,
+		MaxConnectionAgeGrace: cfg.MaxConnectionAgeGrace,
+		Time:                  cfg.KeepaliveTime,
+		Timeout:               cfg.KeepaliveTimeout,
+	}
+	if params != (keepalive.ServerParameters{}) {
+		opts = append(opts, grpc.KeepaliveParams(params))
+	}
+
+	if cfg.KeepaliveMinTime > 0 {
+		opts = append(opts, grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             cfg.KeepaliveMinTime,
+			PermitWithoutStream: true,
+		}))
+	}
+
+	return opts
+}
+
+// SetServing marks service as serving in health checks, empty service is the whole server.
+func (s *Server) SetServing(service string) {
+	s.setServingStatus(service, grpc_health_v1.HealthCheckResponse_SERVING)
+}
+
+// SetNotServing marks service as not serving in health checks, empty service is the whole server.
+func (s *Server) SetNotServing(service string) {
+	s.setServingStatus(service, grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+}
+
+// setServingStatus warns when health service is not registered, nobody would see the status.
+func (s *Server) setServingStatus(service string, status grpc_health_v1.HealthCheckResponse_ServingStatus) {
+	if !s.config.HealthEnabled {
+		slog.Warn("gRPC health service is not registered, serving status is not reported",
+			slog.String("service", service), slog.String("status", status.String()))
+		return
 	}
+
+	s.health.SetServingStatus(service, status)
 }
 
 func (s *Server) Serve(cfg config.GRPC) error {
@@ -39,6 +180,57 @@ func (s *Server) Serve(cfg config.GRPC) error {
 	return s.Server.Serve(listener)
 }
 
+// Run serves on the configured port until ctx is cancelled, then reports not serving in
+// health checks and drains in-flight calls for the shutdown timeout before forcing Stop.
+func (s *Server) Run(ctx context.Context) error {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%s", s.config.Port))
+	if err != nil {
+		return err
+	}
+
+	return s.RunListener(ctx, listener)
+}
+
+// RunListener is Run on the given listener.
+func (s *Server) RunListener(ctx context.Context, listener net.Listener) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- s.Server.Serve(listener)
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	s.health.Shutdown()
+
+	timeout := s.config.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = DefaultShutdownTimeout
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		s.Server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(timeout):
+		s.Server.Stop()
+	}
+
+	if err := <-serveErr; err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		return err
+	}
+
+	return nil
+}
+
 func (s *Server) GracefulStop() {
+	s.health.Shutdown()
 	s.Server.GracefulStop()
 }
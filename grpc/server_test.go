@@ -0,0 +1,106 @@
+package grpc
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/stepanbukhtii/easy-tools/config"
+)
+
+func TestServerRun(t *testing.T) {
+	server, err := NewServerConfig(config.GRPC{
+		HealthEnabled:     true,
+		ReflectionEnabled: true,
+		ShutdownTimeout:   200 * time.Millisecond,
+		MaxRecvMsgSize:    1024,
+		KeepaliveTime:     time.Minute,
+		KeepaliveMinTime:  time.Second,
+	})
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runErr := make(chan error, 1)
+	go func() { runErr <- server.RunListener(ctx, listener) }()
+
+	conn, err := NewClientConnection(listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	healthClient := grpc_health_v1.NewHealthClient(conn)
+
+	check := func(service string) (grpc_health_v1.HealthCheckResponse_ServingStatus, error) {
+		resp, err := healthClient.Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: service})
+		return resp.GetStatus(), err
+	}
+
+	serving, err := check("")
+	require.NoError(t, err)
+	require.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, serving)
+
+	server.SetServing("orders")
+	serving, err = check("orders")
+	require.NoError(t, err)
+	require.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, serving)
+
+	server.SetNotServing("orders")
+	serving, err = check("orders")
+	require.NoError(t, err)
+	require.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, serving)
+
+	_, err = check("unknown")
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	reflectionStream, err := grpc_reflection_v1.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	require.NoError(t, err)
+	require.NoError(t, reflectionStream.Send(&grpc_reflection_v1.ServerReflectionRequest{
+		MessageRequest: &grpc_reflection_v1.ServerReflectionRequest_ListServices{},
+	}))
+	reflectionResp, err := reflectionStream.Recv()
+	require.NoError(t, err)
+	require.NotEmpty(t, reflectionResp.GetListServicesResponse().GetService())
+
+	// open stream keeps graceful stop waiting until the shutdown timeout forces stop
+	watchCtx, watchCancel := context.WithCancel(context.Background())
+	defer watchCancel()
+	_, err = healthClient.Watch(watchCtx, &grpc_health_v1.HealthCheckRequest{})
+	require.NoError(t, err)
+
+	start := time.Now()
+	cancel()
+
+	select {
+	case err := <-runErr:
+		require.NoError(t, err)
+		require.Less(t, time.Since(start), 5*time.Second)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not stop")
+	}
+}
+
+func TestServerHealthDisabled(t *testing.T) {
+	var logWriter bytes.Buffer
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&logWriter, nil)))
+	defer slog.SetDefault(defaultLogger)
+
+	server := NewServer()
+	server.SetServing("orders")
+
+	require.Contains(t, logWriter.String(), "gRPC health service is not registered")
+	require.NotContains(t, server.GetServiceInfo(), grpc_health_v1.Health_ServiceDesc.ServiceName)
+}
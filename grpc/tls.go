@@ -0,0 +1,238 @@
+package grpc
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/stepanbukhtii/easy-tools/config"
+)
+
+// Client auth modes of config.GRPC.TLSClientAuth.
+const (
+	ClientAuthNone             = "none"
+	ClientAuthRequest          = "request"
+	ClientAuthRequire          = "require"
+	ClientAuthVerifyIfGiven    = "verify"
+	ClientAuthRequireAndVerify = "require_and_verify"
+)
+
+// CertReloadInterval is how often certificate files are checked for changes.
+var CertReloadInterval = 30 * time.Second
+
+var (
+	ErrUnknownClientAuth = errors.New("unknown tls client auth mode")
+	ErrInvalidCA         = errors.New("no certificates in ca file")
+)
+
+// CertReloader serves key pair from disk and reloads it when files change, so rotated
+// certificates are picked up by new handshakes without restart.
+type CertReloader struct {
+	certFile string
+	keyFile  string
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	modTime   time.Time
+	checkedAt time.Time
+}
+
+func NewCertReloader(certFile, keyFile string) (*CertReloader, error) {
+	r := &CertReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.certificate()
+}
+
+func (r *CertReloader) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return r.certificate()
+}
+
+func (r *CertReloader) certificate() (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if time.Since(r.checkedAt) >= CertReloadInterval {
+		r.checkedAt = time.Now()
+
+		// keep serving the previous certificate while files are being replaced
+		if modTime, err := r.latestModTime(); err == nil && modTime.After(r.modTime) {
+			_ = r.load(modTime)
+		}
+	}
+
+	return r.cert, nil
+}
+
+func (r *CertReloader) reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	modTime, err := r.latestModTime()
+	if err != nil {
+		return err
+	}
+
+	r.checkedAt = time.Now()
+
+	return r.load(modTime)
+}
+
+func (r *CertReloader) load(modTime time.Time) error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+
+	r.cert = &cert
+	r.modTime = modTime
+
+	return nil
+}
+
+func (r *CertReloader) latestModTime() (time.Time, error) {
+	var latest time.Time
+	for _, file := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+// NewServerTLSConfig returns server TLS config, with CA file and client auth mode set
+// client certificates are verified for mTLS.
+func NewServerTLSConfig(cfg config.GRPC) (*tls.Config, error) {
+	reloader, err := NewCertReloader(cfg.TLSCertFile, cfg.TLSKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: reloader.GetCertificate,
+	}
+
+	if tlsConfig.ClientAuth, err = clientAuthType(cfg.TLSClientAuth, cfg.TLSCAFile != ""); err != nil {
+		return nil, err
+	}
+
+	if cfg.TLSCAFile != "" {
+		if tlsConfig.ClientCAs, err = loadCertPool(cfg.TLSCAFile); err != nil {
+			return nil, err
+		}
+	}
+
+	return tlsConfig, nil
+}
+
+// NewClientTLSConfig returns client TLS config, CA file replaces system roots and
+// certificate with key are presented to servers requiring mTLS.
+func NewClientTLSConfig(cfg config.GRPC) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: cfg.TLSServerName,
+	}
+
+	if cfg.TLSCAFile != "" {
+		var err error
+		if tlsConfig.RootCAs, err = loadCertPool(cfg.TLSCAFile); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.TLSCertFile != "" {
+		reloader, err := NewCertReloader(cfg.TLSCertFile, cfg.TLSKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.GetClientCertificate = reloader.GetClientCertificate
+	}
+
+	return tlsConfig, nil
+}
+
+// ServerCredentials returns TLS credentials or insecure ones when TLS is disabled.
+func ServerCredentials(cfg config.GRPC) (credentials.TransportCredentials, error) {
+	if !cfg.TLSEnabled {
+		return insecure.NewCredentials(), nil
+	}
+
+	tlsConfig, err := NewServerTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+// ClientCredentials returns TLS credentials or insecure ones when TLS is disabled.
+func ClientCredentials(cfg config.GRPC) (credentials.TransportCredentials, error) {
+	if !cfg.TLSEnabled {
+		return insecure.NewCredentials(), nil
+	}
+
+	tlsConfig, err := NewClientTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+func clientAuthType(mode string, hasCA bool) (tls.ClientAuthType, error) {
+	switch mode {
+	case "":
+		if hasCA {
+			return tls.RequireAndVerifyClientCert, nil
+		}
+		return tls.NoClientCert, nil
+	case ClientAuthNone:
+		return tls.NoClientCert, nil
+	case ClientAuthRequest:
+		return tls.RequestClientCert, nil
+	case ClientAuthRequire:
+		return tls.RequireAnyClientCert, nil
+	case ClientAuthVerifyIfGiven:
+		return tls.VerifyClientCertIfGiven, nil
+	case ClientAuthRequireAndVerify:
+		return tls.RequireAndVerifyClientCert, nil
+	}
+
+	return tls.NoClientCert, fmt.Errorf("%w: %s", ErrUnknownClientAuth, mode)
+}
+
+func loadCertPool(caFile string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, ErrInvalidCA
+	}
+
+	return pool, nil
+}
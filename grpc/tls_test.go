@@ -0,0 +1,182 @@
+package grpc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/stepanbukhtii/easy-tools/config"
+	"github.com/stepanbukhtii/easy-tools/econtext"
+	"github.com/stepanbukhtii/easy-tools/grpc/interceptor"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T) testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return testCA{cert: cert, key: key}
+}
+
+func (ca testCA) writeCert(t *testing.T, dir, name string, serial int64, template x509.Certificate) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template.SerialNumber = big.NewInt(serial)
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+	template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile := filepath.Join(dir, name+".crt")
+	keyFile := filepath.Join(dir, name+".key")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+
+	return certFile, keyFile
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+
+	caFile := filepath.Join(dir, "ca.crt")
+	require.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.cert.Raw}), 0o600))
+
+	serverCert, serverKey := ca.writeCert(t, dir, "server", 2, x509.Certificate{
+		Subject:  pkix.Name{CommonName: "server"},
+		DNSNames: []string{"localhost"},
+	})
+
+	spiffeID, err := url.Parse("spiffe://example.org/orders")
+	require.NoError(t, err)
+
+	clientCert, clientKey := ca.writeCert(t, dir, "client", 3, x509.Certificate{
+		Subject: pkix.Name{CommonName: "orders"},
+		URIs:    []*url.URL{spiffeID},
+	})
+
+	subjects := make(chan string, 1)
+	captureSubject := func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		subjects <- econtext.ClientInfo(ctx).Subject
+		return handler(ctx, req)
+	}
+
+	server, err := NewServerConfig(config.GRPC{
+		TLSEnabled:    true,
+		TLSCertFile:   serverCert,
+		TLSKeyFile:    serverKey,
+		TLSCAFile:     caFile,
+		TLSClientAuth: ClientAuthRequireAndVerify,
+	}, interceptor.PeerSubject, captureSubject)
+	require.NoError(t, err)
+
+	grpc_health_v1.RegisterHealthServer(server, health.NewServer())
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	go func() { _ = server.Server.Serve(listener) }()
+	defer server.Stop()
+
+	check := func(cfg config.GRPC) error {
+		conn, err := NewClientConnectionConfig(listener.Addr().String(), cfg)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		_, err = grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+		return err
+	}
+
+	clientConfig := config.GRPC{
+		TLSEnabled:    true,
+		TLSCertFile:   clientCert,
+		TLSKeyFile:    clientKey,
+		TLSCAFile:     caFile,
+		TLSServerName: "localhost",
+	}
+
+	require.NoError(t, check(clientConfig))
+	require.Equal(t, spiffeID.String(), <-subjects)
+
+	withoutCert := clientConfig
+	withoutCert.TLSCertFile, withoutCert.TLSKeyFile = "", ""
+	require.Error(t, check(withoutCert))
+}
+
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+
+	certFile, keyFile := ca.writeCert(t, dir, "server", 2, x509.Certificate{Subject: pkix.Name{CommonName: "server"}})
+
+	reloader, err := NewCertReloader(certFile, keyFile)
+	require.NoError(t, err)
+
+	serial := func() int64 {
+		cert, err := reloader.GetCertificate(&tls.ClientHelloInfo{})
+		require.NoError(t, err)
+
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		require.NoError(t, err)
+
+		return leaf.SerialNumber.Int64()
+	}
+
+	require.Equal(t, int64(2), serial())
+
+	defer func(interval time.Duration) { CertReloadInterval = interval }(CertReloadInterval)
+	CertReloadInterval = 0
+
+	ca.writeCert(t, dir, "server", 3, x509.Certificate{Subject: pkix.Name{CommonName: "server"}})
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(certFile, future, future))
+	require.NoError(t, os.Chtimes(keyFile, future, future))
+
+	require.Equal(t, int64(3), serial())
+}
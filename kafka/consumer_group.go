@@ -124,7 +124,7 @@ func (c *ConsumerGroup) handle(record *kgo.Record) {
 	}
 
 	if len(record.Value) > 0 {
-		logger = logger.With(slog.String(elog.MessagingMessageBodyContent, string(record.Value)))
+		logger = logger.With(slog.String(elog.MessagingMessageBodyContent, elog.DefaultRedactor.JSON(record.Value)))
 	}
 
 	start := time.Now()
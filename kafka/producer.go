@@ -54,7 +54,7 @@ func (p *Producer) ProduceRaw(ctx context.Context, topic string, body []byte) er
 
 	logger := econtext.Logger(ctx).With(
 		slog.String(string(semconv.MessagingDestinationNameKey), topic),
-		slog.String(elog.MessagingMessageBodyContent, string(body)),
+		slog.String(elog.MessagingMessageBodyContent, elog.DefaultRedactor.JSON(body)),
 	)
 
 	if err := p.client.ProduceSync(ctx, record).FirstErr(); err != nil {
@@ -107,7 +107,7 @@ func (p *Subscriber) handle(handler HandlerFunc) nats.MsgHandler {
 		)
 
 		if len(msg.Data) > 0 {
-			logger = logger.With(slog.String(elog.MessagingMessageBodyContent, string(msg.Data)))
+			logger = logger.With(slog.String(elog.MessagingMessageBodyContent, elog.DefaultRedactor.JSON(msg.Data)))
 		}
 
 		start := time.Now()
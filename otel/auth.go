@@ -0,0 +1,52 @@
+package otel
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/stepanbukhtii/easy-tools/econtext"
+	"github.com/stepanbukhtii/easy-tools/ejwt"
+	"github.com/stepanbukhtii/easy-tools/rest/api"
+)
+
+const authScheme = "Bearer"
+
+type authTransport struct {
+	transport http.RoundTripper
+	tokens    ejwt.TokenSource
+	audience  string
+}
+
+// NewAuthTransport sets Authorization header of outgoing requests, user token from
+// econtext.AuthToken is forwarded, otherwise service token for the audience is used.
+// Requests with Authorization header already set are left untouched.
+func NewAuthTransport(transport http.RoundTripper, tokens ejwt.TokenSource, audience string) http.RoundTripper {
+	return &authTransport{
+		transport: transport,
+		tokens:    tokens,
+		audience:  audience,
+	}
+}
+
+func (t *authTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if r.Header.Get(api.HeaderAuthorization) != "" {
+		return t.transport.RoundTrip(r)
+	}
+
+	token := econtext.AuthToken(r.Context())
+	if token == "" {
+		var err error
+		if token, err = t.tokens.Token(r.Context(), t.audience); err != nil {
+			// RoundTripper must close the body even on errors
+			if r.Body != nil {
+				_ = r.Body.Close()
+			}
+			return nil, fmt.Errorf("service token: %w", err)
+		}
+	}
+
+	r = r.Clone(r.Context())
+	r.Header.Set(api.HeaderAuthorization, fmt.Sprintf("%s %s", authScheme, token))
+
+	return t.transport.RoundTrip(r)
+}
@@ -5,12 +5,19 @@ import (
 	"net/http"
 
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+
+	"github.com/stepanbukhtii/easy-tools/ejwt"
 )
 
 func NewHTTPClient(serviceName string) *http.Client {
 	return NewHTTPClientTransport(serviceName, http.DefaultTransport)
 }
 
+// NewHTTPClientAuth returns traced client authenticating requests with NewAuthTransport.
+func NewHTTPClientAuth(serviceName string, tokens ejwt.TokenSource, audience string) *http.Client {
+	return NewHTTPClientTransport(serviceName, NewAuthTransport(http.DefaultTransport, tokens, audience))
+}
+
 func NewHTTPClientTransport(serviceName string, transport http.RoundTripper) *http.Client {
 	return &http.Client{
 		Transport: otelhttp.NewTransport(
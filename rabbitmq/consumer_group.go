@@ -193,7 +193,7 @@ func (c *ConsumerGroup) handle(baseCtx context.Context, consumer ConsumerOptions
 	}
 
 	if len(msg.Body) > 0 {
-		logger = logger.With(slog.String(elog.MessagingMessageBodyContent, string(msg.Body)))
+		logger = logger.With(slog.String(elog.MessagingMessageBodyContent, elog.DefaultRedactor.JSON(msg.Body)))
 	}
 
 	start := time.Now()
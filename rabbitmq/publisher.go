@@ -118,7 +118,7 @@ func (p *Publisher) publish(ctx context.Context, exchange, routingKey string, bo
 
 	logger := econtext.Logger(spanCtx).With(
 		slog.String(string(semconv.MessagingDestinationNameKey), destination),
-		slog.String(elog.MessagingMessageBodyContent, string(body)),
+		slog.String(elog.MessagingMessageBodyContent, elog.DefaultRedactor.JSON(body)),
 	)
 
 	channel, err := p.getChannel(ctx)
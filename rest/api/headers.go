@@ -11,4 +11,8 @@ const (
 	HeaderUserAgent         = "User-Agent"
 	HeaderDeviceID          = "X-Device-ID"
 	HeaderContentTypeOption = "X-Content-Type-Options"
+	HeaderSignature         = "X-Signature"
+	HeaderSignatureKeyID    = "X-Signature-Key-ID"
+	HeaderSignatureNonce    = "X-Signature-Nonce"
+	HeaderSignatureTime     = "X-Signature-Timestamp"
 )
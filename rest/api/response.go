@@ -1,6 +1,7 @@
 package api
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -13,6 +14,13 @@ const (
 	StatusError   = "error"
 )
 
+// Bearer token error codes as defined by RFC 6750 section 3.1.
+const (
+	BearerErrorInvalidRequest    = "invalid_request"
+	BearerErrorInvalidToken      = "invalid_token"
+	BearerErrorInsufficientScope = "insufficient_scope"
+)
+
 type Response struct {
 	Status string `json:"status"`
 	Error  string `json:"error,omitempty"`
@@ -79,6 +87,25 @@ func RespondUnauthorized(c *gin.Context, err error) {
 	RespondStatusError(c, http.StatusUnauthorized, err)
 }
 
+// RespondBearerUnauthorized responds 401 with a WWW-Authenticate challenge carrying
+// the bearer error code and the error as its description.
+func RespondBearerUnauthorized(c *gin.Context, bearerError string, err error) {
+	RespondBearerStatusError(c, http.StatusUnauthorized, bearerError, err)
+}
+
+// RespondBearerForbidden responds 403 with a WWW-Authenticate challenge, used with
+// BearerErrorInsufficientScope.
+func RespondBearerForbidden(c *gin.Context, bearerError string, err error) {
+	RespondBearerStatusError(c, http.StatusForbidden, bearerError, err)
+}
+
+// RespondBearerStatusError responds code with a WWW-Authenticate challenge, RFC 6750 uses
+// 400 for BearerErrorInvalidRequest.
+func RespondBearerStatusError(c *gin.Context, code int, bearerError string, err error) {
+	c.Header(HeaderAuthenticate, bearerChallenge(bearerError, err))
+	RespondStatusError(c, code, err)
+}
+
 func RespondForbidden(c *gin.Context, err error) {
 	RespondStatusError(c, http.StatusForbidden, err)
 }
@@ -148,3 +175,17 @@ func errorText(err error) string {
 	}
 	return err.Error()
 }
+
+func bearerChallenge(bearerError string, err error) string {
+	challenge := "Bearer realm=\"api\""
+	if bearerError == "" {
+		return challenge
+	}
+
+	challenge += fmt.Sprintf(", error=%q", bearerError)
+	if err != nil {
+		challenge += fmt.Sprintf(", error_description=%q", err.Error())
+	}
+
+	return challenge
+}
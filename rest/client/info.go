@@ -6,7 +6,10 @@ var DefaultLocale = LocaleEN
 
 type Info struct {
 	Subject   string
+	Email     string
+	Name      string
 	Roles     []string
+	Scopes    []string
 	Locale    string
 	IPAddress string
 	UserAgent string
@@ -0,0 +1,79 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stepanbukhtii/easy-tools/grpc/interceptor"
+	"github.com/stepanbukhtii/easy-tools/rest/api"
+	"google.golang.org/grpc/metadata"
+)
+
+type ginContextKey struct{}
+
+// NewGatewayMux creates grpc-gateway mux which forwards client info and allowed log fields set
+// by gin middlewares to gRPC metadata and renders errors in api.Response envelope. The
+// Authorization header is forwarded by the gateway itself. Nil propagation forwards client
+// info only, opts are applied after the defaults.
+func NewGatewayMux(propagation *interceptor.Propagation, opts ...runtime.ServeMuxOption) *runtime.ServeMux {
+	if propagation == nil {
+		propagation = interceptor.NewPropagation()
+	}
+
+	defaultOpts := []runtime.ServeMuxOption{
+		runtime.WithMetadata(func(ctx context.Context, _ *http.Request) metadata.MD {
+			return propagation.Metadata(ctx)
+		}),
+		runtime.WithErrorHandler(GatewayErrorHandler),
+	}
+
+	return runtime.NewServeMux(append(defaultOpts, opts...)...)
+}
+
+// MountGateway routes requests under prefix, e.g. "/v1", to the gateway mux, so they pass
+// the router middlewares and errors are rendered with the gin context.
+func MountGateway(r gin.IRouter, prefix string, mux http.Handler) {
+	r.Any(strings.TrimSuffix(prefix, "/")+"/*path", func(c *gin.Context) {
+		ctx := context.WithValue(c.Request.Context(), ginContextKey{}, c)
+		mux.ServeHTTP(c.Writer, c.Request.WithContext(ctx))
+	})
+}
+
+// GatewayErrorHandler renders gRPC status as api.Response with HTTP status of the code,
+// error details converted by interceptor.StatusToError are set to response data.
+func GatewayErrorHandler(
+	ctx context.Context,
+	_ *runtime.ServeMux,
+	_ runtime.Marshaler,
+	w http.ResponseWriter,
+	r *http.Request,
+	err error,
+) {
+	code := runtime.HTTPStatusFromCode(interceptor.ErrorCode(err))
+	err = interceptor.StatusToError(err)
+
+	var data any
+	var statusErr *interceptor.StatusError
+	if errors.As(err, &statusErr) {
+		data = statusErr.ResponseData
+	}
+
+	if code == http.StatusUnauthorized {
+		w.Header().Set(api.HeaderAuthenticate, "Bearer realm=\"api\"")
+	}
+
+	if c, ok := ctx.Value(ginContextKey{}).(*gin.Context); ok {
+		api.RespondStatusErrorData(c, code, err, data)
+		return
+	}
+
+	w.Header().Set(api.HeaderContentType, "application/json; charset=utf-8")
+	w.Header().Set(api.HeaderContentTypeOption, "nosniff")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(api.NewErrorResponseData(err, data))
+}
@@ -0,0 +1,98 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stepanbukhtii/easy-tools/config"
+	"github.com/stepanbukhtii/easy-tools/errx"
+	"github.com/stepanbukhtii/easy-tools/grpc/interceptor"
+	"github.com/stepanbukhtii/easy-tools/rest/api"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestGateway(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name             string
+		path             string
+		err              error
+		expectedCode     int
+		expectedResponse string
+	}{
+		{
+			name:             "success",
+			path:             "/v1/items/1",
+			expectedCode:     http.StatusOK,
+			expectedResponse: `{"id":"1"}`,
+		}, {
+			name:             "not found",
+			path:             "/v1/items/1",
+			err:              fmt.Errorf("item: %w", api.ErrNotFound),
+			expectedCode:     http.StatusNotFound,
+			expectedResponse: `{"status":"error","error":"item: base.not_found"}`,
+		}, {
+			name: "validation with fields",
+			path: "/v1/items/1",
+			err: errx.Wrap(api.ErrValidation, "item").WithResponseData(map[string]string{
+				"name": "required",
+			}),
+			expectedCode:     http.StatusBadRequest,
+			expectedResponse: `{"status":"error","error":"item: base.validation","data":{"name":"required"}}`,
+		}, {
+			name:             "routing error",
+			path:             "/v1/unknown",
+			expectedCode:     http.StatusNotFound,
+			expectedResponse: `{"status":"error","error":"Not Found"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var md metadata.MD
+
+			mux := NewGatewayMux(nil)
+			err := mux.HandlePath(http.MethodGet, "/v1/items/{id}",
+				func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+					ctx, err := runtime.AnnotateIncomingContext(r.Context(), mux, r, "/test.v1.Service/GetItem")
+					require.NoError(t, err)
+					md, _ = metadata.FromIncomingContext(ctx)
+
+					if test.err != nil {
+						runtime.HTTPError(ctx, mux, &runtime.JSONPb{}, w, r, interceptor.ErrorStatus(test.err).Err())
+						return
+					}
+
+					_ = json.NewEncoder(w).Encode(map[string]string{"id": pathParams["id"]})
+				})
+			require.NoError(t, err)
+
+			router := NewRouter(config.API{CORSOrigins: []string{"http://localhost"}}, config.Service{Name: "test"}, WithGateway("/v1", mux))
+
+			req := httptest.NewRequestWithContext(context.Background(), http.MethodGet, test.path, nil)
+			req.Header.Set(api.HeaderAuthorization, "Bearer token")
+			req.Header.Set(api.HeaderAcceptLanguage, "uk")
+			req.Header.Set(api.HeaderDeviceID, "device")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, test.expectedCode, w.Code)
+			require.JSONEq(t, test.expectedResponse, w.Body.String())
+
+			if md != nil {
+				require.Equal(t, []string{"Bearer token"}, md.Get("authorization"))
+				require.Equal(t, []string{"uk"}, md.Get(interceptor.MetadataLocale))
+				require.Equal(t, []string{"device"}, md.Get(interceptor.MetadataDeviceID))
+			}
+		})
+	}
+}
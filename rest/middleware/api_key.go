@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/stepanbukhtii/easy-tools/apikey"
+	"github.com/stepanbukhtii/easy-tools/rest/api"
+)
+
+type APIKeyAuth struct {
+	authenticator *apikey.Authenticator
+	header        string
+}
+
+// NewAPIKeyAuth reads key from header, empty header uses apikey.DefaultHeader.
+func NewAPIKeyAuth(authenticator *apikey.Authenticator, header string) *APIKeyAuth {
+	if header == "" {
+		header = apikey.DefaultHeader
+	}
+
+	return &APIKeyAuth{
+		authenticator: authenticator,
+		header:        header,
+	}
+}
+
+// Auth verifying API key and attaching its owner to client info
+func (m APIKeyAuth) Auth(c *gin.Context) {
+	key, err := m.authenticator.Authenticate(c.Request.Context(), c.GetHeader(m.header))
+	switch {
+	case errors.Is(err, apikey.ErrKeyMissing), errors.Is(err, apikey.ErrKeyInvalid), errors.Is(err, apikey.ErrKeyExpired):
+		api.RespondUnauthorized(c, err)
+		return
+	case err != nil:
+		api.RespondInternalError(c, err)
+		return
+	}
+
+	c.Request = c.Request.WithContext(key.SetContext(c.Request.Context()))
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stepanbukhtii/easy-tools/apikey"
+	"github.com/stepanbukhtii/easy-tools/econtext"
+)
+
+func TestAPIKeyAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	plainKey, hash, err := apikey.Generate("")
+	require.NoError(t, err)
+
+	authenticator := apikey.NewAuthenticator(apikey.NewMemoryStore(apikey.Key{Hash: hash, Subject: testSubject}))
+	defer authenticator.Close()
+
+	middleware := NewAPIKeyAuth(authenticator, "")
+
+	tests := []struct {
+		name           string
+		key            string
+		expectedStatus int
+	}{
+		{name: "success", key: plainKey, expectedStatus: http.StatusOK},
+		{name: "invalid key", key: "invalid", expectedStatus: http.StatusUnauthorized},
+		{name: "no key", expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+			if test.key != "" {
+				c.Request.Header.Set(apikey.DefaultHeader, test.key)
+			}
+
+			middleware.Auth(c)
+
+			require.Equal(t, test.expectedStatus, c.Writer.Status())
+			if test.expectedStatus == http.StatusOK {
+				require.Equal(t, testSubject, econtext.ClientInfo(c.Request.Context()).Subject)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/stepanbukhtii/easy-tools/authz"
+	"github.com/stepanbukhtii/easy-tools/rest/api"
+)
+
+// Authorize checks policy against client info set by authentication middleware
+func Authorize(authorizer *authz.Authorizer, policy authz.Policy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		err := authorizer.AuthorizeContext(c.Request.Context(), policy)
+		switch {
+		case errors.Is(err, authz.ErrUnauthenticated):
+			api.RespondUnauthorized(c, err)
+		case err != nil:
+			api.RespondForbidden(c, err)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stepanbukhtii/easy-tools/authz"
+	"github.com/stepanbukhtii/easy-tools/econtext"
+	"github.com/stepanbukhtii/easy-tools/rest/client"
+)
+
+func TestAuthorize(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	authorizer := authz.NewAuthorizer().Inherit("admin", "editor")
+
+	tests := []struct {
+		name           string
+		clientInfo     client.Info
+		expectedStatus int
+	}{
+		{
+			name:           "allowed",
+			clientInfo:     client.Info{Subject: testSubject, Roles: []string{"admin"}},
+			expectedStatus: http.StatusOK,
+		}, {
+			name:           "forbidden",
+			clientInfo:     client.Info{Subject: testSubject, Roles: []string{"viewer"}},
+			expectedStatus: http.StatusForbidden,
+		}, {
+			name:           "unauthenticated",
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+			c.Request = c.Request.WithContext(econtext.SetClientInfo(c.Request.Context(), test.clientInfo))
+
+			Authorize(authorizer, authz.AnyRole("editor"))(c)
+
+			require.Equal(t, test.expectedStatus, c.Writer.Status())
+		})
+	}
+}
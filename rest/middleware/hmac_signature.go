@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"resty.dev/v3"
+
+	"github.com/stepanbukhtii/easy-tools/crypto"
+	"github.com/stepanbukhtii/easy-tools/rest/api"
+)
+
+// DefaultMaxSignedBodySize limits body read by HMACSignature.Verify before the signature is checked.
+var DefaultMaxSignedBodySize int64 = 1 << 20 // 1 MB
+
+type HMACSignatureOption func(m *HMACSignature)
+
+// WithSignatureMaxBodySize replaces DefaultMaxSignedBodySize.
+func WithSignatureMaxBodySize(size int64) HMACSignatureOption {
+	return func(m *HMACSignature) { m.maxBodySize = size }
+}
+
+type HMACSignature struct {
+	verifier    *crypto.HMACVerifier
+	maxBodySize int64
+}
+
+func NewHMACSignature(verifier *crypto.HMACVerifier, opts ...HMACSignatureOption) *HMACSignature {
+	m := &HMACSignature{
+		verifier:    verifier,
+		maxBodySize: DefaultMaxSignedBodySize,
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
+}
+
+// Verify checks request signature headers, used for incoming webhooks and partner calls
+func (m HMACSignature) Verify(c *gin.Context) {
+	timestamp, err := strconv.ParseInt(c.GetHeader(api.HeaderSignatureTime), 10, 64)
+	if err != nil {
+		api.RespondUnauthorized(c, crypto.ErrSignatureMissing)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, m.maxBodySize))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			api.RespondStatusError(c, http.StatusRequestEntityTooLarge, err)
+			return
+		}
+		api.RespondBadRequest(c, err)
+		return
+	}
+	c.Request.Body = io.NopCloser(bytes.NewBuffer(body))
+
+	signedRequest := crypto.SignedRequest{
+		KeyID:     c.GetHeader(api.HeaderSignatureKeyID),
+		Method:    c.Request.Method,
+		Path:      c.Request.URL.RequestURI(),
+		Timestamp: timestamp,
+		Nonce:     c.GetHeader(api.HeaderSignatureNonce),
+		Body:      body,
+		Signature: c.GetHeader(api.HeaderSignature),
+	}
+
+	if err := m.verifier.Verify(c.Request.Context(), signedRequest); err != nil {
+		api.RespondUnauthorized(c, err)
+		return
+	}
+}
+
+var ErrRawRequestNotPrepared = errors.New("raw request not prepared")
+
+// HMACSignRequest is resty request middleware adding signature headers to outgoing requests.
+// It signs the final HTTP request, so it must run after resty.PrepareRequestMiddleware:
+//
+//	client.SetRequestMiddlewares(resty.PrepareRequestMiddleware, middleware.HMACSignRequest(signer))
+func HMACSignRequest(signer *crypto.HMACSigner) resty.RequestMiddleware {
+	return func(_ *resty.Client, r *resty.Request) error {
+		rawRequest := r.RawRequest
+		if rawRequest == nil {
+			return ErrRawRequestNotPrepared
+		}
+
+		var body []byte
+		if rawRequest.GetBody != nil {
+			bodyReader, err := rawRequest.GetBody()
+			if err != nil {
+				return err
+			}
+
+			if body, err = io.ReadAll(bodyReader); err != nil {
+				return err
+			}
+		}
+
+		signedRequest, err := signer.Sign(rawRequest.Method, rawRequest.URL.RequestURI(), body)
+		if err != nil {
+			return err
+		}
+
+		rawRequest.Header.Set(api.HeaderSignatureKeyID, signedRequest.KeyID)
+		rawRequest.Header.Set(api.HeaderSignatureTime, strconv.FormatInt(signedRequest.Timestamp, 10))
+		rawRequest.Header.Set(api.HeaderSignatureNonce, signedRequest.Nonce)
+		rawRequest.Header.Set(api.HeaderSignature, signedRequest.Signature)
+
+		return nil
+	}
+}
@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"resty.dev/v3"
+
+	"github.com/stepanbukhtii/easy-tools/cache"
+	"github.com/stepanbukhtii/easy-tools/crypto"
+	"github.com/stepanbukhtii/easy-tools/rest/api"
+)
+
+func TestHMACSignature(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	secret := crypto.HMACSecret{ID: "partner", Key: []byte("secret")}
+
+	nonces := cache.NewMemory[bool](time.Hour, time.Minute)
+	defer nonces.Close()
+
+	verifier := crypto.NewHMACVerifier([]crypto.HMACSecret{secret}, time.Minute, nonces)
+
+	router := gin.New()
+	router.POST("/webhook", NewHMACSignature(verifier).Verify, func(c *gin.Context) { api.RespondOK(c) })
+	router.POST("/limited", NewHMACSignature(verifier, WithSignatureMaxBodySize(8)).Verify,
+		func(c *gin.Context) { api.RespondOK(c) })
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	t.Run("signed", func(t *testing.T) {
+		client := resty.New().SetRequestMiddlewares(
+			resty.PrepareRequestMiddleware,
+			HMACSignRequest(crypto.NewHMACSigner(secret)),
+		)
+		defer client.Close()
+
+		resp, err := client.R().SetBody(map[string]string{"event": "payment"}).Post(server.URL + "/webhook?id=1")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode())
+	})
+
+	t.Run("wrong secret", func(t *testing.T) {
+		signer := crypto.NewHMACSigner(crypto.HMACSecret{ID: "partner", Key: []byte("wrong")})
+		client := resty.New().SetRequestMiddlewares(resty.PrepareRequestMiddleware, HMACSignRequest(signer))
+		defer client.Close()
+
+		resp, err := client.R().SetBody(map[string]string{"event": "payment"}).Post(server.URL + "/webhook")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode())
+	})
+
+	t.Run("not signed", func(t *testing.T) {
+		client := resty.New()
+		defer client.Close()
+
+		resp, err := client.R().SetBody(map[string]string{"event": "payment"}).Post(server.URL + "/webhook")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode())
+	})
+
+	t.Run("replay", func(t *testing.T) {
+		body := `{"event":"payment"}`
+		signedRequest, err := crypto.NewHMACSigner(secret).Sign(http.MethodPost, "/webhook", []byte(body))
+		require.NoError(t, err)
+
+		send := func(keyID string) int {
+			req, err := http.NewRequest(http.MethodPost, server.URL+"/webhook", strings.NewReader(body))
+			require.NoError(t, err)
+			if keyID != "" {
+				req.Header.Set(api.HeaderSignatureKeyID, keyID)
+			}
+			req.Header.Set(api.HeaderSignatureTime, strconv.FormatInt(signedRequest.Timestamp, 10))
+			req.Header.Set(api.HeaderSignatureNonce, signedRequest.Nonce)
+			req.Header.Set(api.HeaderSignature, signedRequest.Signature)
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			return resp.StatusCode
+		}
+
+		require.Equal(t, http.StatusOK, send(secret.ID))
+		require.Equal(t, http.StatusUnauthorized, send(secret.ID))
+		require.Equal(t, http.StatusUnauthorized, send(""))
+		require.Equal(t, http.StatusUnauthorized, send("other"))
+	})
+
+	t.Run("body too large", func(t *testing.T) {
+		client := resty.New().SetRequestMiddlewares(
+			resty.PrepareRequestMiddleware,
+			HMACSignRequest(crypto.NewHMACSigner(secret)),
+		)
+		defer client.Close()
+
+		resp, err := client.R().SetBody(map[string]string{"event": "payment"}).Post(server.URL + "/limited")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode())
+	})
+}
@@ -3,10 +3,10 @@ package middleware
 import (
 	"crypto/ed25519"
 	"errors"
-	"fmt"
+	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/golang-jwt/jwt/v5/request"
 
 	"github.com/stepanbukhtii/easy-tools/econtext"
@@ -15,128 +15,209 @@ import (
 )
 
 var (
-	NoAuthorizationHeader = errors.New("no authorization header")
-	FailedToParseToken    = errors.New("failed to parse token")
-	InvalidToken          = errors.New("invalid token")
+	NoAuthorizationHeader      = errors.New("no authorization header")
+	InvalidAuthorizationHeader = errors.New("invalid authorization header")
+	FailedToParseToken         = errors.New("failed to parse token")
+	InvalidToken               = errors.New("invalid token")
+	InsufficientRole           = errors.New("insufficient role")
 )
 
+// JWTAuthOption configures JWTAuth.
+type JWTAuthOption func(*JWTAuth)
+
+// WithTokenCookie additionally reads the token from the named cookie when the
+// Authorization header is absent.
+func WithTokenCookie(name string) JWTAuthOption {
+	return func(m *JWTAuth) {
+		m.extractors = append(m.extractors, cookieExtractor(name))
+	}
+}
+
+// WithTokenQuery additionally reads the token from the named query parameter when
+// the Authorization header is absent, e.g. for websocket and SSE endpoints where
+// browsers cannot set headers.
+func WithTokenQuery(param string) JWTAuthOption {
+	return func(m *JWTAuth) {
+		m.extractors = append(m.extractors, request.ArgumentExtractor{param})
+	}
+}
+
 type JWTAuth struct {
-	publicKey      ed25519.PublicKey
-	parser         *jwt.Parser
+	verifier       *ejwt.Verifier
 	skipValidation bool
+	extractors     request.MultiExtractor
 }
 
-func NewJWTAuth(publicKey ed25519.PublicKey, issuer, audience string, skipValidation bool) *JWTAuth {
-	options := []jwt.ParserOption{
-		jwt.WithValidMethods([]string{jwt.SigningMethodEdDSA.Alg()}),
-		jwt.WithIssuer(issuer),
-		jwt.WithAudience(audience),
-		jwt.WithIssuedAt(),
-		jwt.WithExpirationRequired(),
-	}
+func NewJWTAuth(publicKey ed25519.PublicKey, issuer, audience string, skipValidation bool, opts ...JWTAuthOption) *JWTAuth {
+	return NewJWTAuthVerifier(ejwt.NewVerifier(publicKey, issuer, audience), skipValidation, opts...)
+}
 
-	return &JWTAuth{
-		publicKey:      publicKey,
-		parser:         jwt.NewParser(options...),
+func NewJWTAuthVerifier(verifier *ejwt.Verifier, skipValidation bool, opts ...JWTAuthOption) *JWTAuth {
+	m := &JWTAuth{
+		verifier:       verifier,
 		skipValidation: skipValidation,
+		extractors:     request.MultiExtractor{bearerHeaderExtractor{}},
 	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
 }
 
 // Auth parse and verifying JWT token
 func (m JWTAuth) Auth(c *gin.Context) {
-	tokenString, err := request.AuthorizationHeaderExtractor.ExtractToken(c.Request)
-	if err != nil {
-		api.RespondUnauthorized(c, NoAuthorizationHeader)
+	claims, ok := m.authenticate(c)
+	if !ok {
 		return
 	}
 
-	c.Request = c.Request.WithContext(econtext.SetAuthToken(c.Request.Context(), tokenString))
-
-	var claims jwt.RegisteredClaims
-
-	if m.skipValidation {
-		if _, _, err := m.parser.ParseUnverified(tokenString, &claims); err != nil {
-			api.RespondUnauthorized(c, FailedToParseToken)
-			return
-		}
-
-		if claims.Subject != "" {
-			c.Request = c.Request.WithContext(econtext.SetSubject(c.Request.Context(), claims.Subject))
-		}
+	setClaims(c, claims)
+}
 
+// OptionalAuth parse and verifying JWT token if it is present. Requests without a
+// valid token pass through anonymously with econtext left untouched, revocation store
+// errors respond 500.
+func (m JWTAuth) OptionalAuth(c *gin.Context) {
+	tokenString, err := m.extractors.ExtractToken(c.Request)
+	if err != nil {
 		return
 	}
 
-	keyFunc := func(token *jwt.Token) (interface{}, error) { return m.publicKey, nil }
-	token, err := m.parser.ParseWithClaims(tokenString, &claims, keyFunc)
-	if err != nil {
-		api.RespondUnauthorized(c, FailedToParseToken)
+	claims, err := m.parseToken(c, tokenString)
+	if errors.Is(err, ejwt.ErrRevocationCheck) {
+		respondRevocationCheckFailed(c, err)
 		return
 	}
-
-	if !token.Valid || claims.Subject == "" {
-		api.RespondUnauthorized(c, InvalidToken)
+	if err != nil {
 		return
 	}
 
-	c.Request = c.Request.WithContext(econtext.SetSubject(c.Request.Context(), claims.Subject))
+	c.Request = c.Request.WithContext(econtext.SetAuthToken(c.Request.Context(), tokenString))
+	setClaims(c, claims)
 }
 
 // AuthRole parse and verifying JWT token and check role
 func (m JWTAuth) AuthRole(role string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		tokenString, err := request.AuthorizationHeaderExtractor.ExtractToken(c.Request)
-		if err != nil {
-			api.RespondUnauthorized(c, NoAuthorizationHeader)
+		claims, ok := m.authenticate(c)
+		if !ok {
 			return
 		}
 
-		c.Request = c.Request.WithContext(econtext.SetAuthToken(c.Request.Context(), tokenString))
+		if !m.skipValidation && !claims.HasRole(role) {
+			api.RespondBearerForbidden(c, api.BearerErrorInsufficientScope, InsufficientRole)
+			return
+		}
+
+		setClaims(c, claims)
+	}
+}
+
+// authenticate extracts and verifies the token, responding 401 when it is missing or invalid.
+func (m JWTAuth) authenticate(c *gin.Context) (ejwt.Claims, bool) {
+	tokenString, err := m.extractors.ExtractToken(c.Request)
+	if errors.Is(err, InvalidAuthorizationHeader) {
+		api.RespondBearerStatusError(c, http.StatusBadRequest, api.BearerErrorInvalidRequest, err)
+		return ejwt.Claims{}, false
+	}
+	if err != nil {
+		api.RespondBearerUnauthorized(c, "", NoAuthorizationHeader)
+		return ejwt.Claims{}, false
+	}
+
+	c.Request = c.Request.WithContext(econtext.SetAuthToken(c.Request.Context(), tokenString))
 
-		var claims ejwt.ClaimsWithRoles
+	claims, err := m.parseToken(c, tokenString)
+	if errors.Is(err, ejwt.ErrRevocationCheck) {
+		respondRevocationCheckFailed(c, err)
+		return claims, false
+	}
+	if err != nil {
+		api.RespondBearerUnauthorized(c, api.BearerErrorInvalidToken, err)
+		return claims, false
+	}
 
-		if m.skipValidation {
-			if _, _, err := m.parser.ParseUnverified(tokenString, &claims); err != nil {
-				fmt.Println(err)
-				api.RespondUnauthorized(c, FailedToParseToken)
-				return
-			}
+	return claims, true
+}
 
-			if claims.Subject != "" {
-				c.Request = c.Request.WithContext(econtext.SetSubject(c.Request.Context(), claims.Subject))
-			}
+// respondRevocationCheckFailed responds 500 keeping the store error only in gin errors,
+// store outage is not a client credentials problem.
+func respondRevocationCheckFailed(c *gin.Context, err error) {
+	_ = c.Error(err)
+	api.RespondInternalError(c, ejwt.ErrRevocationCheck)
+}
 
-			if claims.Roles != nil {
-				c.Request = c.Request.WithContext(econtext.SetRoles(c.Request.Context(), claims.Roles))
-			}
+func (m JWTAuth) parseToken(c *gin.Context, tokenString string) (ejwt.Claims, error) {
+	var claims ejwt.Claims
 
-			return
+	if m.skipValidation {
+		if err := m.verifier.ParseUnverified(tokenString, &claims); err != nil {
+			return claims, FailedToParseToken
 		}
 
-		keyFunc := func(_ *jwt.Token) (any, error) { return m.publicKey, nil }
-		token, err := m.parser.ParseWithClaims(tokenString, &claims, keyFunc)
-		if err != nil {
-			api.RespondUnauthorized(c, FailedToParseToken)
-			return
-		}
+		return claims, nil
+	}
 
-		var hasRole bool
-		for _, claimRole := range claims.Roles {
-			if claimRole == role {
-				hasRole = true
-				break
-			}
-		}
+	claims, err := m.verifier.VerifyClaimsContext(c.Request.Context(), tokenString)
+	switch {
+	case errors.Is(err, ejwt.ErrTokenRevoked):
+		return claims, ejwt.ErrTokenRevoked
+	case errors.Is(err, ejwt.ErrRevocationCheck):
+		return claims, err
+	case errors.Is(err, ejwt.ErrInvalidToken), errors.Is(err, ejwt.ErrEmptySubject):
+		return claims, InvalidToken
+	case err != nil:
+		return claims, FailedToParseToken
+	}
 
-		if !token.Valid || claims.Subject == "" || !hasRole {
-			api.RespondUnauthorized(c, InvalidToken)
-			return
-		}
+	return claims, nil
+}
 
-		ctx := c.Request.Context()
+func setClaims(c *gin.Context, claims ejwt.Claims) {
+	ctx := c.Request.Context()
+
+	if claims.Subject != "" {
 		ctx = econtext.SetSubject(ctx, claims.Subject)
+	}
+
+	if claims.Roles != nil {
 		ctx = econtext.SetRoles(ctx, claims.Roles)
+	}
+
+	if scopes := claims.Scopes(); scopes != nil {
+		ctx = econtext.SetScopes(ctx, scopes)
+	}
+
+	c.Request = c.Request.WithContext(ctx)
+}
+
+// bearerHeaderExtractor reads "Bearer <token>" Authorization header, other schemes are
+// rejected with InvalidAuthorizationHeader instead of being parsed as a token.
+type bearerHeaderExtractor struct{}
 
-		c.Request = c.Request.WithContext(ctx)
+func (bearerHeaderExtractor) ExtractToken(r *http.Request) (string, error) {
+	header := r.Header.Get(api.HeaderAuthorization)
+	if header == "" {
+		return "", request.ErrNoTokenInRequest
 	}
+
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "bearer") || token == "" {
+		return "", InvalidAuthorizationHeader
+	}
+
+	return token, nil
+}
+
+type cookieExtractor string
+
+func (e cookieExtractor) ExtractToken(r *http.Request) (string, error) {
+	cookie, err := r.Cookie(string(e))
+	if err != nil || cookie.Value == "" {
+		return "", request.ErrNoTokenInRequest
+	}
+
+	return cookie.Value, nil
 }
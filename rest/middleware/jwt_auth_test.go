@@ -1,9 +1,11 @@
 package middleware
 
 import (
+	"context"
 	"crypto/ed25519"
 	"crypto/rand"
 	"crypto/rsa"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -16,6 +18,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/stepanbukhtii/easy-tools/cache"
 	"github.com/stepanbukhtii/easy-tools/econtext"
 	"github.com/stepanbukhtii/easy-tools/ejwt"
 )
@@ -254,7 +257,7 @@ func TestMiddleware_AuthRole(t *testing.T) {
 				Roles: []string{"invalid_role"},
 			},
 			signPrivateKey: privateKey,
-			expectedStatus: http.StatusUnauthorized,
+			expectedStatus: http.StatusForbidden,
 		}, {
 			name:          "skip validation role",
 			signingMethod: jwt.SigningMethodEdDSA,
@@ -297,7 +300,8 @@ func TestMiddleware_AuthRole(t *testing.T) {
 			token, err := jwt.NewWithClaims(test.signingMethod, test.registeredClaims).SignedString(test.signPrivateKey)
 			assert.NoError(t, err)
 
-			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			recorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(recorder)
 			c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
 			c.Request.Header.Set(api.HeaderAuthorization, fmt.Sprintf("Bearer %s", token))
 
@@ -307,6 +311,10 @@ func TestMiddleware_AuthRole(t *testing.T) {
 			middleware.AuthRole(testRole)(c)
 
 			require.Equal(t, test.expectedStatus, c.Writer.Status())
+			if c.Writer.Status() == http.StatusForbidden {
+				require.Equal(t, `Bearer realm="api", error="insufficient_scope", error_description="insufficient role"`,
+					recorder.Header().Get(api.HeaderAuthenticate))
+			}
 			if c.Writer.Status() == http.StatusOK {
 				require.Equal(t, test.registeredClaims.Subject, econtext.ClientInfo(c.Request.Context()).Subject)
 				require.Equal(t, test.registeredClaims.Roles, econtext.ClientInfo(c.Request.Context()).Roles)
@@ -314,3 +322,254 @@ func TestMiddleware_AuthRole(t *testing.T) {
 		})
 	}
 }
+
+func TestMiddleware_AuthKeySet(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	rsaPrivateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	_, edPrivateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	keys := ejwt.NewKeySet()
+	require.NoError(t, keys.Add("rsa", &rsaPrivateKey.PublicKey))
+
+	middleware := NewJWTAuthVerifier(ejwt.NewVerifierKeySource(keys, testIssuer, testAudience), false)
+
+	tests := []struct {
+		name           string
+		signingKey     ejwt.SigningKey
+		expectedStatus int
+	}{
+		{
+			name:           "success",
+			signingKey:     ejwt.SigningKey{ID: "rsa", Key: rsaPrivateKey},
+			expectedStatus: http.StatusOK,
+		}, {
+			name:           "unknown key id",
+			signingKey:     ejwt.SigningKey{ID: "ed", Key: edPrivateKey},
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			jwtGenerator, err := ejwt.NewJWTGeneratorKey(test.signingKey, testIssuer, testAudience, time.Minute)
+			require.NoError(t, err)
+
+			token, err := jwtGenerator.GenerateToken(testSubject)
+			require.NoError(t, err)
+
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+			c.Request.Header.Set(api.HeaderAuthorization, fmt.Sprintf("Bearer %s", token))
+
+			middleware.Auth(c)
+
+			require.Equal(t, test.expectedStatus, c.Writer.Status())
+		})
+	}
+}
+
+func TestMiddleware_AuthRevoked(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	revocationCache := cache.NewMemory[int64](time.Hour, time.Minute)
+	defer revocationCache.Close()
+
+	store := ejwt.NewCacheRevocationStore(revocationCache, time.Minute)
+	verifier := ejwt.NewVerifier(publicKey, testIssuer, testAudience, ejwt.WithRevocationStore(store))
+	middleware := NewJWTAuthVerifier(verifier, false)
+
+	jwtGenerator := ejwt.NewJWTGenerator(privateKey, testIssuer, testAudience, time.Minute)
+	claims := jwtGenerator.NewClaims(testSubject)
+
+	token, err := ejwt.GenerateTokenClaims(&jwtGenerator, claims)
+	require.NoError(t, err)
+
+	auth := func() int {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+		c.Request.Header.Set(api.HeaderAuthorization, fmt.Sprintf("Bearer %s", token))
+
+		middleware.Auth(c)
+
+		return c.Writer.Status()
+	}
+
+	require.Equal(t, http.StatusOK, auth())
+
+	require.NoError(t, store.Revoke(context.Background(), claims))
+
+	require.Equal(t, http.StatusUnauthorized, auth())
+}
+
+type failingRevocationStore struct {
+	ejwt.RevocationStore
+}
+
+func (failingRevocationStore) IsRevoked(context.Context, ejwt.Claims) (bool, error) {
+	return false, errors.New("revocation store down")
+}
+
+func TestMiddleware_AuthRevocationStoreFailed(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	verifier := ejwt.NewVerifier(publicKey, testIssuer, testAudience, ejwt.WithRevocationStore(failingRevocationStore{}))
+	middleware := NewJWTAuthVerifier(verifier, false)
+
+	jwtGenerator := ejwt.NewJWTGenerator(privateKey, testIssuer, testAudience, time.Minute)
+	token, err := jwtGenerator.GenerateToken(testSubject)
+	require.NoError(t, err)
+
+	for name, handler := range map[string]gin.HandlerFunc{
+		"auth":          middleware.Auth,
+		"optional auth": middleware.OptionalAuth,
+	} {
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+			c.Request.Header.Set(api.HeaderAuthorization, fmt.Sprintf("Bearer %s", token))
+
+			handler(c)
+
+			require.Equal(t, http.StatusInternalServerError, c.Writer.Status())
+			require.Empty(t, w.Header().Get(api.HeaderAuthenticate))
+			require.NotContains(t, w.Body.String(), "revocation store down")
+		})
+	}
+}
+
+func TestMiddleware_OptionalAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	_, wrongPrivateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	jwtGenerator := ejwt.NewJWTGenerator(privateKey, testIssuer, testAudience, time.Minute)
+	token, err := jwtGenerator.GenerateToken(testSubject)
+	require.NoError(t, err)
+
+	wrongJWTGenerator := ejwt.NewJWTGenerator(wrongPrivateKey, testIssuer, testAudience, time.Minute)
+	wrongToken, err := wrongJWTGenerator.GenerateToken(testSubject)
+	require.NoError(t, err)
+
+	middleware := NewJWTAuth(publicKey, testIssuer, testAudience, false)
+
+	tests := []struct {
+		name            string
+		authorization   string
+		expectedSubject string
+	}{
+		{
+			name:            "valid token",
+			authorization:   fmt.Sprintf("Bearer %s", token),
+			expectedSubject: testSubject,
+		}, {
+			name:          "invalid token",
+			authorization: fmt.Sprintf("Bearer %s", wrongToken),
+		}, {
+			name: "no token",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+			if test.authorization != "" {
+				c.Request.Header.Set(api.HeaderAuthorization, test.authorization)
+			}
+
+			middleware.OptionalAuth(c)
+
+			require.Equal(t, http.StatusOK, c.Writer.Status())
+			require.False(t, c.IsAborted())
+			require.Equal(t, test.expectedSubject, econtext.ClientInfo(c.Request.Context()).Subject)
+		})
+	}
+}
+
+func TestMiddleware_AuthTokenSources(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	jwtGenerator := ejwt.NewJWTGenerator(privateKey, testIssuer, testAudience, time.Minute)
+	token, err := jwtGenerator.GenerateToken(testSubject)
+	require.NoError(t, err)
+
+	middleware := NewJWTAuth(publicKey, testIssuer, testAudience, false,
+		WithTokenCookie("access_token"), WithTokenQuery("token"))
+
+	tests := []struct {
+		name                 string
+		prepare              func(r *http.Request)
+		expectedStatus       int
+		expectedAuthenticate string
+	}{
+		{
+			name:           "cookie",
+			prepare:        func(r *http.Request) { r.AddCookie(&http.Cookie{Name: "access_token", Value: token}) },
+			expectedStatus: http.StatusOK,
+		}, {
+			name:           "query",
+			prepare:        func(r *http.Request) { r.URL.RawQuery = "token=" + token },
+			expectedStatus: http.StatusOK,
+		}, {
+			name:                 "invalid query token",
+			prepare:              func(r *http.Request) { r.URL.RawQuery = "token=invalid" },
+			expectedStatus:       http.StatusUnauthorized,
+			expectedAuthenticate: `Bearer realm="api", error="invalid_token", error_description="failed to parse token"`,
+		}, {
+			name:                 "no token",
+			prepare:              func(r *http.Request) {},
+			expectedStatus:       http.StatusUnauthorized,
+			expectedAuthenticate: `Bearer realm="api"`,
+		}, {
+			name:                 "wrong scheme",
+			prepare:              func(r *http.Request) { r.Header.Set(api.HeaderAuthorization, "Basic "+token) },
+			expectedStatus:       http.StatusBadRequest,
+			expectedAuthenticate: `Bearer realm="api", error="invalid_request", error_description="invalid authorization header"`,
+		}, {
+			name:                 "bearer without token",
+			prepare:              func(r *http.Request) { r.Header.Set(api.HeaderAuthorization, "Bearer") },
+			expectedStatus:       http.StatusBadRequest,
+			expectedAuthenticate: `Bearer realm="api", error="invalid_request", error_description="invalid authorization header"`,
+		}, {
+			name:           "lower case scheme",
+			prepare:        func(r *http.Request) { r.Header.Set(api.HeaderAuthorization, "bearer "+token) },
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(recorder)
+			c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+			test.prepare(c.Request)
+
+			middleware.Auth(c)
+
+			require.Equal(t, test.expectedStatus, c.Writer.Status())
+			require.Equal(t, test.expectedAuthenticate, recorder.Header().Get(api.HeaderAuthenticate))
+			if test.expectedStatus == http.StatusOK {
+				require.Equal(t, testSubject, econtext.ClientInfo(c.Request.Context()).Subject)
+				require.Equal(t, token, econtext.AuthToken(c.Request.Context()))
+			}
+		})
+	}
+}
@@ -45,7 +45,7 @@ func Logger(c *gin.Context) {
 	)
 
 	if len(bodyBytes) > 0 {
-		logger = logger.With(slog.String(elog.HTTPRequestBodyContent, string(bodyBytes)))
+		logger = logger.With(slog.String(elog.HTTPRequestBodyContent, elog.DefaultRedactor.JSON(bodyBytes)))
 	}
 
 	var loggerErr error
@@ -14,7 +14,14 @@ import (
 
 var DefaultOTELSkipPath = []string{"/health", "/swagger-ui", "/swagger-config", "/swagger"}
 
-func NewRouter(apiConfig config.API, serviceConfig config.Service) *gin.Engine {
+type RouterOption func(r *gin.Engine)
+
+// WithGateway mounts grpc-gateway mux under prefix, see MountGateway.
+func WithGateway(prefix string, mux http.Handler) RouterOption {
+	return func(r *gin.Engine) { MountGateway(r, prefix, mux) }
+}
+
+func NewRouter(apiConfig config.API, serviceConfig config.Service, opts ...RouterOption) *gin.Engine {
 	r := gin.New()
 
 	gin.DisableBindValidation()
@@ -40,6 +47,10 @@ func NewRouter(apiConfig config.API, serviceConfig config.Service) *gin.Engine {
 		api.RespondOK(c)
 	})
 
+	for _, opt := range opts {
+		opt(r)
+	}
+
 	return r
 }
 